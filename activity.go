@@ -0,0 +1,350 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const activityInterval = 2 * time.Second
+
+var activitySorts = []string{"duration", "pid", "state", "user", "client"}
+
+const activityQuery = `
+SELECT pid, coalesce(usename, ''), coalesce(datname, ''), coalesce(application_name, ''),
+       coalesce(client_addr::text, ''), coalesce(state, ''), coalesce(wait_event_type, ''),
+       coalesce(wait_event, ''), query_start, coalesce(query, ''), coalesce(backend_type, '')
+FROM pg_stat_activity
+WHERE pid <> pg_backend_pid()`
+
+type backend struct {
+	pid         int
+	user        string
+	database    string
+	application string
+	client      string
+	state       string
+	waitType    string
+	waitEvent   string
+	queryStart  sql.NullTime
+	query       string
+	backendType string
+}
+
+func (b backend) duration(now time.Time) time.Duration {
+	if !b.queryStart.Valid {
+		return 0
+	}
+	return now.Sub(b.queryStart.Time)
+}
+
+func (b backend) wait() string {
+	if b.waitType == "" {
+		return ""
+	}
+	return b.waitType + ":" + b.waitEvent
+}
+
+// activityMonitor is the live pg_stat_activity view.
+type activityMonitor struct {
+	backends   []backend
+	sortBy     string
+	cursor     int
+	filter     textinput.Model
+	confirm    string // "cancel" or "terminate" while waiting for y/n
+	refreshed  time.Time
+	generation int
+}
+
+type activityTickMsg struct {
+	generation int
+}
+
+func newActivityMonitor() activityMonitor {
+	filter := textinput.New()
+	filter.Prompt = "Filter: "
+	return activityMonitor{sortBy: "duration", filter: filter}
+}
+
+func getBackends(db *sql.DB) ([]backend, error) {
+	rows, err := db.Query(activityQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var backends []backend
+	for rows.Next() {
+		var b backend
+		if err := rows.Scan(&b.pid, &b.user, &b.database, &b.application, &b.client, &b.state,
+			&b.waitType, &b.waitEvent, &b.queryStart, &b.query, &b.backendType); err != nil {
+			return nil, err
+		}
+		backends = append(backends, b)
+	}
+	return backends, rows.Err()
+}
+
+func (a activityMonitor) tick() tea.Cmd {
+	generation := a.generation
+	return tea.Tick(activityInterval, func(time.Time) tea.Msg {
+		return activityTickMsg{generation: generation}
+	})
+}
+
+func (m *model) openActivityMonitor() tea.Cmd {
+	if !m.requirePostgres("The activity monitor") {
+		return nil
+	}
+	m.activity.generation++
+	m.activity.confirm = ""
+	m.showActivity = true
+	m.editor.Blur()
+	m.refreshActivity()
+	return m.activity.tick()
+}
+
+func (m *model) closeActivityMonitor() {
+	m.showActivity = false
+	m.activity.generation++
+	m.focusState = focusEditor
+	m.dbList.SetFilteringEnabled(false)
+	m.editor.Focus()
+}
+
+// refreshActivity reloads the backends and keeps the cursor on the same PID.
+func (m *model) refreshActivity() {
+	backends, err := getBackends(m.db)
+	if err != nil {
+		m.setQueryError(err)
+		return
+	}
+	pid := m.activity.selectedPID()
+	m.activity.backends = backends
+	m.activity.refreshed = time.Now()
+	m.activity.selectPID(pid)
+}
+
+func (m model) updateActivityTick(msg activityTickMsg) (tea.Model, tea.Cmd) {
+	if !m.showActivity || msg.generation != m.activity.generation {
+		return m, nil
+	}
+	if m.activity.confirm == "" {
+		m.refreshActivity()
+	}
+	return m, m.activity.tick()
+}
+
+// visible returns the filtered backends in display order.
+func (a activityMonitor) visible() []backend {
+	filter := strings.ToLower(strings.TrimSpace(a.filter.Value()))
+	var backends []backend
+	for _, b := range a.backends {
+		haystack := strings.ToLower(strings.Join([]string{strconv.Itoa(b.pid), b.user, b.database,
+			b.application, b.client, b.state, b.wait(), b.query, b.backendType}, " "))
+		if filter == "" || strings.Contains(haystack, filter) {
+			backends = append(backends, b)
+		}
+	}
+
+	now := time.Now()
+	sort.SliceStable(backends, func(i, j int) bool {
+		x, y := backends[i], backends[j]
+		switch a.sortBy {
+		case "pid":
+			return x.pid < y.pid
+		case "state":
+			return x.state < y.state
+		case "user":
+			return x.user < y.user
+		case "client":
+			return x.client < y.client
+		}
+		return x.duration(now) > y.duration(now)
+	})
+	return backends
+}
+
+func (a activityMonitor) selectedPID() int {
+	visible := a.visible()
+	if a.cursor < len(visible) {
+		return visible[a.cursor].pid
+	}
+	return 0
+}
+
+func (a *activityMonitor) selectPID(pid int) {
+	visible := a.visible()
+	for i, b := range visible {
+		if b.pid == pid {
+			a.cursor = i
+			return
+		}
+	}
+	a.cursor = clamp(a.cursor, 0, max(len(visible)-1, 0))
+}
+
+func (m *model) signalBackend(action string, pid int) {
+	function := "pg_cancel_backend"
+	if action == "terminate" {
+		function = "pg_terminate_backend"
+	}
+	var ok bool
+	if err := m.db.QueryRow("SELECT "+function+"($1)", pid).Scan(&ok); err != nil {
+		m.setQueryError(err)
+		return
+	}
+	if !ok {
+		m.queryError = fmt.Sprintf("%s(%d) returned false: the backend is gone or not yours", function, pid)
+		return
+	}
+	m.statusMessage = fmt.Sprintf("Sent %s to %d", action, pid)
+	m.refreshActivity()
+}
+
+func (m model) updateActivity(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	a := &m.activity
+	if a.filter.Focused() {
+		switch msg.String() {
+		case "enter", "esc":
+			if msg.String() == "esc" {
+				a.filter.SetValue("")
+			}
+			a.filter.Blur()
+			a.cursor = 0
+		default:
+			var cmd tea.Cmd
+			a.filter, cmd = a.filter.Update(msg)
+			a.cursor = 0
+			return m, cmd
+		}
+		return m, nil
+	}
+
+	if a.confirm != "" {
+		if msg.String() == "y" {
+			m.signalBackend(a.confirm, a.selectedPID())
+		}
+		a.confirm = ""
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "esc", "q":
+		m.closeActivityMonitor()
+	case "up", "k":
+		a.cursor = max(a.cursor-1, 0)
+	case "down", "j":
+		a.cursor = min(a.cursor+1, max(len(a.visible())-1, 0))
+	case "g", "home":
+		a.cursor = 0
+	case "G", "end":
+		a.cursor = max(len(a.visible())-1, 0)
+	case "s":
+		pid := a.selectedPID()
+		a.sortBy = cycleString(activitySorts, a.sortBy, 1)
+		a.selectPID(pid)
+	case "/":
+		return m, a.filter.Focus()
+	case "ctrl+r":
+		m.refreshActivity()
+	case "c":
+		if a.selectedPID() != 0 {
+			a.confirm = "cancel"
+		}
+	case "T":
+		if a.selectedPID() != 0 {
+			a.confirm = "terminate"
+		}
+	case "y":
+		visible := a.visible()
+		if a.cursor < len(visible) {
+			m.copyToClipboard(visible[a.cursor].query, "query")
+		}
+	}
+	return m, nil
+}
+
+func formatDuration(d time.Duration) string {
+	switch {
+	case d <= 0:
+		return ""
+	case d < time.Minute:
+		return fmt.Sprintf("%.1fs", d.Seconds())
+	case d < time.Hour:
+		return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+	}
+	return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+}
+
+func stateStyle(state string) lipgloss.Style {
+	switch state {
+	case "active":
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+	case "idle in transaction", "idle in transaction (aborted)":
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("11"))
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+}
+
+func (m model) activityView() string {
+	a := m.activity
+	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("6"))
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+	selected := lipgloss.NewStyle().Foreground(lipgloss.Color("229")).Background(lipgloss.Color("57"))
+	width := m.TotalWidth - 8
+	height := max(m.RHeight-6, 4)
+	visible := a.visible()
+
+	lines := []string{title.Render(fmt.Sprintf("Activity · %d backends · sorted by %s · %s",
+		len(visible), a.sortBy, a.refreshed.Format("15:04:05")))}
+	switch {
+	case a.confirm != "":
+		lines = append(lines, lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("196")).
+			Render(fmt.Sprintf("%s backend %d? (y/n)", strings.ToUpper(a.confirm[:1])+a.confirm[1:], a.selectedPID())))
+	case a.filter.Focused() || a.filter.Value() != "":
+		lines = append(lines, a.filter.View())
+	default:
+		lines = append(lines, dim.Render("s: sort · /: filter · c: cancel · T: terminate · y: copy query · ctrl+r: refresh · esc: close"))
+	}
+	lines = append(lines, dim.Render(ansi.Truncate(fmt.Sprintf("%7s  %-12s %-12s %-20s %-22s %9s  %-15s %s",
+		"PID", "USER", "DATABASE", "STATE", "WAIT", "DURATION", "CLIENT", "QUERY"), width, "…")))
+
+	now := time.Now()
+	var rows []string
+	for i, b := range visible {
+		state := b.state
+		if state == "" {
+			state = b.backendType
+		}
+		line := fmt.Sprintf("%7d  %-12s %-12s %-20s %-22s %9s  %-15s %s", b.pid,
+			ansi.Truncate(b.user, 12, "…"), ansi.Truncate(b.database, 12, "…"), ansi.Truncate(state, 20, "…"),
+			ansi.Truncate(b.wait(), 22, "…"), formatDuration(b.duration(now)), ansi.Truncate(b.client, 15, "…"),
+			strings.Join(strings.Fields(b.query), " "))
+		line = ansi.Truncate(line, width, "…")
+		if i == a.cursor {
+			line = selected.Render(line)
+		} else {
+			line = stateStyle(b.state).Render(line)
+		}
+		rows = append(rows, line)
+	}
+
+	// The selected query is shown in full under the list.
+	var detail []string
+	if a.cursor < len(visible) && visible[a.cursor].query != "" {
+		wrapped := ansi.Hardwrap(strings.Join(strings.Fields(visible[a.cursor].query), " "), width, true)
+		detail = strings.Split(wrapped, "\n")
+		detail = detail[:min(len(detail), height/3)]
+	}
+	lines = scrollLines(append(lines, rows...), 3, a.cursor, height-len(detail))
+	return strings.Join(append(lines, detail...), "\n")
+}
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const browsePageSize = 100
+
+// tableBrowser pages through a table without the user writing SQL. Pages
+// are fetched with keyset pagination on the primary key; sorting by another
+// column, or a table without a primary key, falls back to OFFSET.
+type tableBrowser struct {
+	schema     string
+	table      string
+	primaryKey []string
+	filter     textinput.Model
+	where      string
+	sortColumn string
+	sortDesc   bool
+	page       int
+	after      [][]interface{} // keyset start of every page visited so far
+	lastKey    []interface{}   // primary key of the last row on the page, as scanned
+	hasMore    bool
+}
+
+func newTableBrowser(schema, table string, primaryKey []string) tableBrowser {
+	filter := textinput.New()
+	filter.Prompt = "WHERE "
+	filter.Placeholder = "status = 'active'"
+	filter.CharLimit = 1000
+
+	return tableBrowser{
+		schema:     schema,
+		table:      table,
+		primaryKey: primaryKey,
+		filter:     filter,
+		after:      [][]interface{}{nil},
+	}
+}
+
+func (b tableBrowser) source() string {
+	return qualifiedName(b.schema, b.table)
+}
+
+func (b tableBrowser) keyset() bool {
+	return len(b.primaryKey) > 0 && (b.sortColumn == "" || b.sortColumn == b.primaryKey[0])
+}
+
+// query builds the statement for the current page. With inline set, keyset
+// values are written as literals so the statement can be pasted in the
+// editor; otherwise they are returned as arguments.
+func (b tableBrowser) query(inline bool) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	if strings.TrimSpace(b.where) != "" {
+		conditions = append(conditions, "("+b.where+")")
+	}
+
+	direction := "ASC"
+	comparison := ">"
+	if b.sortDesc {
+		direction = "DESC"
+		comparison = "<"
+	}
+
+	var orderBy []string
+	if b.keyset() {
+		if after := b.after[b.page]; after != nil {
+			values := make([]string, len(after))
+			for i, value := range after {
+				if inline {
+					values[i] = activeDriver.quoteLiteral(formatValue(value, ""))
+				} else {
+					args = append(args, value)
+					values[i] = activeDriver.placeholder(len(args))
+				}
+			}
+			conditions = append(conditions, fmt.Sprintf("(%s) %s (%s)",
+				quoteIdentifiers(b.primaryKey), comparison, strings.Join(values, ", ")))
+		}
+		for _, key := range b.primaryKey {
+			orderBy = append(orderBy, activeDriver.quoteIdentifier(key)+" "+direction)
+		}
+	} else if b.sortColumn != "" {
+		orderBy = append(orderBy, activeDriver.quoteIdentifier(b.sortColumn)+" "+direction)
+		for _, key := range b.primaryKey {
+			orderBy = append(orderBy, activeDriver.quoteIdentifier(key))
+		}
+	}
+
+	query := "SELECT * FROM " + b.source()
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	if len(orderBy) > 0 {
+		query += " ORDER BY " + strings.Join(orderBy, ", ")
+	}
+	if inline {
+		query += fmt.Sprintf(" LIMIT %d", browsePageSize)
+	} else {
+		// One extra row tells whether there is a next page.
+		query += fmt.Sprintf(" LIMIT %d", browsePageSize+1)
+	}
+	if !b.keyset() && b.page > 0 {
+		query += fmt.Sprintf(" OFFSET %d", b.page*browsePageSize)
+	}
+	return query, args
+}
+
+func (m *model) openTableBrowser(schema, table string) {
+	primaryKey, err := activeDriver.primaryKey(m.db, qualifiedName(schema, table))
+	if err != nil {
+		m.setQueryError(err)
+		return
+	}
+
+	m.browser = newTableBrowser(schema, table, primaryKey)
+	m.browsing = true
+	m.trail = nil
+	m.result = resultSet{}
+	m.fetchBrowserPage()
+	if m.queryError != "" {
+		m.browsing = false
+		return
+	}
+
+	m.focusState = focusResults
+	m.editor.Blur()
+	m.dbList.SetFilteringEnabled(false)
+	m.resultsTable.Focus()
+}
+
+func (m *model) fetchBrowserPage() {
+	query, args := m.browser.query(false)
+	rows, err := m.db.Query(query, args...)
+	if err != nil {
+		m.setQueryError(err)
+		return
+	}
+	rs, err := scanResultSet(rows)
+	rows.Close()
+	if err != nil {
+		m.setQueryError(err)
+		return
+	}
+
+	m.browser.hasMore = len(rs.rows) > browsePageSize
+	if m.browser.hasMore {
+		rs.rows = rs.rows[:browsePageSize]
+		rs.nulls = rs.nulls[:browsePageSize]
+		rs.values = rs.values[:browsePageSize]
+	}
+	m.browser.lastKey = nil
+	if len(rs.rows) > 0 {
+		m.browser.lastKey, _ = rs.keyValues(len(rs.rows)-1, m.browser.primaryKey)
+	}
+	rs.source = m.browser.source()
+	m.setResults(rs)
+	m.resultsTable.Focus()
+}
+
+// editsBlockPaging reports, and says why, when fetching another page would
+// throw away pending edits.
+func (m *model) editsBlockPaging() bool {
+	if m.edits.active && m.edits.pending() > 0 {
+		m.queryError = "Apply (R) or discard (U) the pending changes before leaving the page"
+		return true
+	}
+	return false
+}
+
+func (m *model) nextBrowserPage() {
+	if !m.browser.hasMore || m.editsBlockPaging() {
+		return
+	}
+	if m.browser.keyset() {
+		m.browser.after = append(m.browser.after[:m.browser.page+1], m.browser.lastKey)
+	}
+	m.browser.page++
+	m.fetchBrowserPage()
+}
+
+func (m *model) previousBrowserPage() {
+	if m.browser.page == 0 || m.editsBlockPaging() {
+		return
+	}
+	m.browser.page--
+	m.fetchBrowserPage()
+}
+
+func (m *model) resetBrowserPaging() {
+	m.browser.page = 0
+	m.browser.after = [][]interface{}{nil}
+	m.fetchBrowserPage()
+}
+
+// cycleBrowserSort sorts by the selected column: ascending, descending, then
+// back to primary-key order.
+func (m *model) cycleBrowserSort() {
+	col := m.selectedColumn()
+	if col < 0 || m.editsBlockPaging() {
+		return
+	}
+	name := m.result.columns[col]
+	switch {
+	case m.browser.sortColumn != name:
+		m.browser.sortColumn, m.browser.sortDesc = name, false
+	case !m.browser.sortDesc:
+		m.browser.sortDesc = true
+	default:
+		m.browser.sortColumn, m.browser.sortDesc = "", false
+	}
+	m.resetBrowserPaging()
+}
+
+// openBrowserQuery drops the statement behind the current page into the
+// editor, on its own line since execution works line by line.
+func (m *model) openBrowserQuery() {
+	query, _ := m.browser.query(true)
+	appendStatement(&m.editor, query+";")
+
+	m.focusState = focusEditor
+	m.resultsTable.Blur()
+	m.editor.Focus()
+}
+
+func (m model) updateBrowser(msg tea.KeyMsg) (model, tea.Cmd, bool) {
+	if m.browser.filter.Focused() {
+		switch msg.String() {
+		case "enter":
+			if m.editsBlockPaging() {
+				m.browser.filter.SetValue(m.browser.where)
+				m.browser.filter.Blur()
+				break
+			}
+			m.browser.where = m.browser.filter.Value()
+			m.browser.filter.Blur()
+			m.resetBrowserPaging()
+		case "esc":
+			m.browser.filter.SetValue(m.browser.where)
+			m.browser.filter.Blur()
+		default:
+			var cmd tea.Cmd
+			m.browser.filter, cmd = m.browser.filter.Update(msg)
+			return m, cmd, true
+		}
+		return m, nil, true
+	}
+
+	switch msg.String() {
+	case "/":
+		m.browser.filter.SetValue(m.browser.where)
+		m.browser.filter.CursorEnd()
+		return m, m.browser.filter.Focus(), true
+	case "]":
+		m.nextBrowserPage()
+	case "[":
+		m.previousBrowserPage()
+	case "s":
+		m.cycleBrowserSort()
+	case "o":
+		m.openBrowserQuery()
+	case "p":
+		return m, m.openProfile(), true
+	case "ctrl+r":
+		if !m.editsBlockPaging() {
+			m.fetchBrowserPage()
+		}
+	default:
+		return m, nil, false
+	}
+	return m, nil, true
+}
+
+func (m model) browserHeader() string {
+	b := m.browser
+	info := fmt.Sprintf("%s · page %d", b.source(), b.page+1)
+	if b.sortColumn != "" {
+		direction := "↑"
+		if b.sortDesc {
+			direction = "↓"
+		}
+		info += fmt.Sprintf(" · sorted by %s %s", b.sortColumn, direction)
+	}
+	if !b.keyset() {
+		info += " · offset paging"
+	}
+	if b.hasMore {
+		info += " · ] next"
+	}
+	if b.page > 0 {
+		info += " · [ previous"
+	}
+
+	filter := b.filter.View()
+	if !b.filter.Focused() {
+		where := b.where
+		if where == "" {
+			where = lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render("press / to filter")
+		}
+		filter = "WHERE " + where
+	}
+
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("6")).Render(info) + "  " + filter
+}
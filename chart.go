@@ -0,0 +1,596 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+var chartKinds = []string{"line", "bar", "sparkline"}
+
+var seriesColors = []lipgloss.Color{"5", "6", "3", "2", "1", "4"}
+
+// resultChart draws the results instead of the grid. Columns are kept by
+// name, so a re-run or watched query redraws with the new rows.
+type resultChart struct {
+	kind    string
+	x       string
+	y       []string
+	picking bool // choosing the columns
+	cursor  int
+}
+
+type chartSeries struct {
+	name   string
+	values []float64
+	valid  []bool // false for NULL or text that isn't a number
+}
+
+// chartData is the results in X order. The X axis is "time", "number" or
+// "category"; on a category axis the rows are evenly spaced.
+type chartData struct {
+	axis   string
+	labels []string
+	xs     []float64
+	series []chartSeries
+}
+
+// numericColumn reports whether every non-NULL value of col is a number.
+func (rs resultSet) numericColumn(col int) bool {
+	found := false
+	for i, row := range rs.rows {
+		if rs.nulls[i][col] {
+			continue
+		}
+		if _, err := strconv.ParseFloat(strings.TrimSpace(row[col]), 64); err != nil {
+			return false
+		}
+		found = true
+	}
+	return found
+}
+
+func (rs resultSet) columnIndex(name string) int {
+	return indexOfString(rs.columns, name)
+}
+
+// xAxis returns the positions of the X values and the kind of axis they
+// make: times or numbers when all of them parse as such.
+func (rs resultSet) xAxis(col int) (string, []float64) {
+	xs := make([]float64, len(rs.rows))
+	axis := "time"
+	for i, row := range rs.rows {
+		t, ok := parseTimeValue(row[col])
+		if !ok {
+			axis = ""
+			break
+		}
+		xs[i] = float64(t.UnixNano()) / 1e9
+	}
+	if axis == "" && rs.numericColumn(col) {
+		axis = "number"
+		for i, row := range rs.rows {
+			xs[i], _ = strconv.ParseFloat(strings.TrimSpace(row[col]), 64)
+		}
+	}
+	if axis == "" {
+		axis = "category"
+		for i := range xs {
+			xs[i] = float64(i)
+		}
+	}
+	return axis, xs
+}
+
+func (m *model) openChart() {
+	rs := m.result
+	if len(rs.rows) == 0 {
+		m.queryError = "No rows to chart"
+		return
+	}
+	if m.chart.kind == "" {
+		m.chart.kind = "line"
+	}
+	// Keep the previous choice when the columns are still there.
+	valid := rs.columnIndex(m.chart.x) >= 0
+	for _, y := range m.chart.y {
+		valid = valid && rs.columnIndex(y) >= 0
+	}
+	if !valid || len(m.chart.y) == 0 {
+		m.chart.x, m.chart.y = "", nil
+		for col := range rs.columns {
+			if axis, _ := rs.xAxis(col); axis == "time" {
+				m.chart.x = rs.columns[col]
+				break
+			}
+		}
+		if m.chart.x == "" {
+			m.chart.x = rs.columns[0]
+		}
+		for col, name := range rs.columns {
+			if name != m.chart.x && rs.numericColumn(col) {
+				m.chart.y = append(m.chart.y, name)
+			}
+		}
+	}
+	m.chart.picking = len(m.chart.y) == 0
+	m.chart.cursor = 0
+	m.showChart = true
+}
+
+func (m model) chartData() chartData {
+	rs := m.result
+	axis, xs := rs.xAxis(rs.columnIndex(m.chart.x))
+	order := make([]int, len(rs.rows))
+	for i := range order {
+		order[i] = i
+	}
+	if axis != "category" {
+		sort.SliceStable(order, func(a, b int) bool { return xs[order[a]] < xs[order[b]] })
+	}
+
+	data := chartData{axis: axis}
+	xCol := rs.columnIndex(m.chart.x)
+	for _, i := range order {
+		data.labels = append(data.labels, rs.rows[i][xCol])
+		data.xs = append(data.xs, xs[i])
+	}
+	for _, name := range m.chart.y {
+		col := rs.columnIndex(name)
+		series := chartSeries{name: name}
+		for _, i := range order {
+			value, err := strconv.ParseFloat(strings.TrimSpace(rs.rows[i][col]), 64)
+			series.values = append(series.values, value)
+			series.valid = append(series.valid, err == nil && !rs.nulls[i][col])
+		}
+		data.series = append(data.series, series)
+	}
+	return data
+}
+
+// valueRange is the lowest and highest Y value, widened when they're equal.
+func (d chartData) valueRange() (float64, float64) {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, series := range d.series {
+		for i, value := range series.values {
+			if series.valid[i] {
+				lo, hi = math.Min(lo, value), math.Max(hi, value)
+			}
+		}
+	}
+	if math.IsInf(lo, 0) {
+		return 0, 1
+	}
+	if lo == hi {
+		return lo - 1, hi + 1
+	}
+	return lo, hi
+}
+
+// xLabel formats a position on the X axis; times are shown with as much
+// precision as the span of the axis needs.
+func (d chartData) xLabel(i int, x float64) string {
+	switch d.axis {
+	case "time":
+		span := d.xs[len(d.xs)-1] - d.xs[0]
+		t := time.Unix(0, int64(x*1e9)).UTC()
+		switch {
+		case span < 2*24*3600:
+			return t.Format("01-02 15:04")
+		case span < 2*365*24*3600:
+			return t.Format("2006-01-02")
+		}
+		return t.Format("2006-01")
+	case "number":
+		return formatNumber(x)
+	}
+	return d.labels[i]
+}
+
+// spreadLabels puts the first label at the left of width cells, the last
+// one at the right and, when it fits, a middle one in the center.
+func spreadLabels(width int, first, middle, last string) string {
+	line := []rune(strings.Repeat(" ", width))
+	put := func(at int, label string) {
+		for i, r := range []rune(label) {
+			if at+i >= 0 && at+i < len(line) {
+				line[at+i] = r
+			}
+		}
+	}
+	put(0, first)
+	at := width/2 - ansi.StringWidth(middle)/2
+	if middle != "" && at > ansi.StringWidth(first) && at+ansi.StringWidth(middle) < width-ansi.StringWidth(last) {
+		put(at, middle)
+	}
+	put(width-ansi.StringWidth(last), last)
+	return string(line)
+}
+
+// brailleCanvas plots dots at 2×4 per cell; the color of a cell is that of
+// the last series drawn into it.
+type brailleCanvas struct {
+	width, height int
+	dots          [][]rune
+	colors        [][]int
+}
+
+func newBrailleCanvas(width, height int) brailleCanvas {
+	c := brailleCanvas{width: width, height: height}
+	for y := 0; y < height; y++ {
+		c.dots = append(c.dots, make([]rune, width))
+		c.colors = append(c.colors, make([]int, width))
+	}
+	return c
+}
+
+var brailleBits = [4][2]rune{{0x01, 0x08}, {0x02, 0x10}, {0x04, 0x20}, {0x40, 0x80}}
+
+func (c *brailleCanvas) set(x, y, color int) {
+	if x < 0 || y < 0 || x >= c.width*2 || y >= c.height*4 {
+		return
+	}
+	c.dots[y/4][x/2] |= brailleBits[y%4][x%2]
+	c.colors[y/4][x/2] = color
+}
+
+func (c *brailleCanvas) line(x0, y0, x1, y1, color int) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	err := dx + dy
+	for {
+		c.set(x0, y0, color)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+func (c brailleCanvas) rows() []string {
+	lines := make([]string, c.height)
+	for y := range c.dots {
+		var line strings.Builder
+		for x, dots := range c.dots[y] {
+			if dots == 0 {
+				line.WriteByte(' ')
+				continue
+			}
+			line.WriteString(lipgloss.NewStyle().Foreground(seriesColors[c.colors[y][x]%len(seriesColors)]).
+				Render(string(0x2800 + dots)))
+		}
+		lines[y] = line.String()
+	}
+	return lines
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func (d chartData) lineChart(width, height int) []string {
+	canvas := newBrailleCanvas(width, height)
+	lo, hi := d.valueRange()
+	first, last := d.xs[0], d.xs[len(d.xs)-1]
+	for s, series := range d.series {
+		previous := -1
+		var px, py int
+		for i, value := range series.values {
+			if !series.valid[i] {
+				previous = -1
+				continue
+			}
+			x := 0
+			if last > first {
+				x = int(math.Round((d.xs[i] - first) / (last - first) * float64(width*2-1)))
+			}
+			y := int(math.Round((hi - value) / (hi - lo) * float64(height*4-1)))
+			if previous >= 0 {
+				canvas.line(px, py, x, y, s)
+			} else {
+				canvas.set(x, y, s)
+			}
+			previous, px, py = i, x, y
+		}
+	}
+	return canvas.rows()
+}
+
+// groupMeans averages the rows into at most n groups of consecutive rows.
+func groupMeans(series chartSeries, n int) []float64 {
+	total := len(series.values)
+	n = min(n, total)
+	means := make([]float64, n)
+	for g := range means {
+		sum, count := 0.0, 0
+		for i := g * total / n; i < (g+1)*total/n; i++ {
+			if series.valid[i] {
+				sum += series.values[i]
+				count++
+			}
+		}
+		means[g] = math.NaN()
+		if count > 0 {
+			means[g] = sum / float64(count)
+		}
+	}
+	return means
+}
+
+var barBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// barChart draws a bar per row and series, side by side; rows are averaged
+// in groups when there are more than fit.
+func (d chartData) barChart(width, height int) []string {
+	lo, hi := d.valueRange()
+	lo, hi = math.Min(lo, 0), math.Max(hi, 0)
+	slot := len(d.series) + 1
+	groups := min(len(d.xs), max(width/slot, 1))
+	barWidth := max((width/groups-1)/len(d.series), 1)
+
+	cells := make([][]string, height)
+	for row := range cells {
+		cells[row] = make([]string, 0, width)
+	}
+	var means [][]float64
+	for _, series := range d.series {
+		means = append(means, groupMeans(series, groups))
+	}
+	for g := range means[0] {
+		for s := range d.series {
+			value := means[s][g]
+			eighths := 0
+			if !math.IsNaN(value) {
+				eighths = int(math.Round((value - lo) / (hi - lo) * float64(height*8)))
+			}
+			style := lipgloss.NewStyle().Foreground(seriesColors[s%len(seriesColors)])
+			for row := 0; row < height; row++ {
+				level := (height - 1 - row) * 8
+				block := " "
+				switch {
+				case eighths >= level+8:
+					block = "█"
+				case eighths > level:
+					block = string(barBlocks[eighths-level-1])
+				}
+				cells[row] = append(cells[row], style.Render(strings.Repeat(block, barWidth)))
+			}
+		}
+		for row := range cells {
+			cells[row] = append(cells[row], " ")
+		}
+	}
+	lines := make([]string, height)
+	for row := range cells {
+		lines[row] = strings.Join(cells[row], "")
+	}
+	return lines
+}
+
+// sparklines draws one line of blocks per series with its range and last
+// value.
+func (d chartData) sparklines(width int) []string {
+	nameWidth := 0
+	for _, series := range d.series {
+		nameWidth = max(nameWidth, ansi.StringWidth(series.name))
+	}
+	nameWidth = min(nameWidth, width/4)
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+
+	var lines []string
+	for s, series := range d.series {
+		lo, hi := chartData{series: []chartSeries{series}}.valueRange()
+		stats := fmt.Sprintf(" min %s · max %s", formatNumber(lo), formatNumber(hi))
+		for i := len(series.values) - 1; i >= 0; i-- {
+			if series.valid[i] {
+				stats += " · last " + formatNumber(series.values[i])
+				break
+			}
+		}
+		sparkWidth := max(width-nameWidth-ansi.StringWidth(stats)-2, 4)
+		var spark strings.Builder
+		for _, mean := range groupMeans(series, sparkWidth) {
+			if math.IsNaN(mean) {
+				spark.WriteByte(' ')
+				continue
+			}
+			spark.WriteRune(barBlocks[int(math.Round((mean-lo)/(hi-lo)*float64(len(barBlocks)-1)))])
+		}
+		name := ansi.Truncate(series.name, nameWidth, "…")
+		lines = append(lines, name+strings.Repeat(" ", nameWidth-ansi.StringWidth(name))+" "+
+			lipgloss.NewStyle().Foreground(seriesColors[s%len(seriesColors)]).Render(spark.String())+dim.Render(stats))
+	}
+	return lines
+}
+
+func (m model) updateChart(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	c := &m.chart
+	if c.picking {
+		switch msg.String() {
+		case "up", "k":
+			c.cursor = max(c.cursor-1, 0)
+		case "down", "j":
+			c.cursor = min(c.cursor+1, max(len(m.result.columns)-1, 0))
+		case "x":
+			c.x = m.result.columns[c.cursor]
+			c.y = removeString(c.y, c.x)
+		case " ":
+			name := m.result.columns[c.cursor]
+			switch {
+			case containsString(c.y, name):
+				c.y = removeString(c.y, name)
+			case name != c.x && m.result.numericColumn(c.cursor):
+				c.y = append(c.y, name)
+			}
+		case "enter":
+			if len(c.y) > 0 {
+				c.picking = false
+			}
+		case "esc", "q":
+			if len(c.y) == 0 {
+				m.showChart = false
+			}
+			c.picking = false
+		}
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "t":
+		c.kind = cycleString(chartKinds, c.kind, 1)
+	case "c":
+		c.picking = true
+		c.cursor = max(m.result.columnIndex(c.x), 0)
+	case "esc", "q":
+		m.showChart = false
+	}
+	return m, nil
+}
+
+func removeString(values []string, s string) []string {
+	var kept []string
+	for _, v := range values {
+		if v != s {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
+
+func (m model) chartPickerView() string {
+	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("6"))
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+	selected := lipgloss.NewStyle().Foreground(lipgloss.Color("229")).Background(lipgloss.Color("57"))
+	height := max(m.RHeight-6, 4)
+
+	lines := []string{
+		title.Render("Chart columns"),
+		dim.Render("x: use as X axis · space: toggle as Y (numbers only) · enter: draw · esc: back"),
+	}
+	nameWidth := 0
+	for _, name := range m.result.columns {
+		nameWidth = max(nameWidth, ansi.StringWidth(name))
+	}
+	var rows []string
+	for col, name := range m.result.columns {
+		marker := "   "
+		switch {
+		case name == m.chart.x:
+			marker = " X "
+		case containsString(m.chart.y, name):
+			marker = " Y "
+		}
+		line := fmt.Sprintf("%s %s  %s", marker, name+strings.Repeat(" ", nameWidth-ansi.StringWidth(name)),
+			dim.Render(strings.ToLower(m.result.types[col])))
+		if col == m.chart.cursor {
+			line = selected.Render(ansi.Strip(line))
+		}
+		rows = append(rows, line)
+	}
+	return strings.Join(scrollLines(append(lines, rows...), 2, m.chart.cursor, height), "\n")
+}
+
+func (m model) chartView() string {
+	if m.chart.picking {
+		return m.chartPickerView()
+	}
+	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("6"))
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+	width := m.TotalWidth - 8
+	height := max(m.RHeight-6, 3)
+
+	var legend []string
+	for s, name := range m.chart.y {
+		legend = append(legend, lipgloss.NewStyle().Foreground(seriesColors[s%len(seriesColors)]).Render("■")+" "+name)
+	}
+	lines := []string{
+		title.Render(fmt.Sprintf("%s chart · %d rows · x: %s", m.chart.kind, len(m.result.rows), m.chart.x)) + "  " +
+			strings.Join(legend, "  "),
+		dim.Render("t: line, bar or sparkline · c: columns · esc: back to the grid"),
+	}
+	if m.result.columnIndex(m.chart.x) < 0 || len(m.chart.y) == 0 {
+		return strings.Join(append(lines, "The chart's columns are not in these results; press c to pick them."), "\n")
+	}
+	for _, name := range m.chart.y {
+		if m.result.columnIndex(name) < 0 {
+			return strings.Join(append(lines, "The chart's columns are not in these results; press c to pick them."), "\n")
+		}
+	}
+	if len(m.result.rows) == 0 {
+		return strings.Join(append(lines, "No rows to chart."), "\n")
+	}
+
+	data := m.chartData()
+	if m.chart.kind == "sparkline" {
+		return strings.Join(append(lines, data.sparklines(width)...), "\n")
+	}
+
+	lo, hi := data.valueRange()
+	if m.chart.kind == "bar" {
+		lo, hi = math.Min(lo, 0), math.Max(hi, 0)
+	}
+	labelWidth := max(max(len(formatNumber(lo)), len(formatNumber(hi))), len(formatNumber((lo+hi)/2)))
+	plotWidth := max(width-labelWidth-2, 10)
+	plotHeight := max(height-3, 2)
+
+	var plot []string
+	if m.chart.kind == "bar" {
+		plot = data.barChart(plotWidth, plotHeight)
+	} else {
+		plot = data.lineChart(plotWidth, plotHeight)
+	}
+	for row, line := range plot {
+		label := ""
+		switch row {
+		case 0:
+			label = formatNumber(hi)
+		case plotHeight / 2:
+			label = formatNumber((lo + hi) / 2)
+		case plotHeight - 1:
+			label = formatNumber(lo)
+		}
+		lines = append(lines, dim.Render(fmt.Sprintf("%*s ┤", labelWidth, label))+line)
+	}
+	// Bars are evenly spaced whatever the axis, so only the ends are labelled.
+	n := len(data.xs)
+	first, last := data.xLabel(0, data.xs[0]), data.xLabel(n-1, data.xs[n-1])
+	xLabels := spreadLabels(ansi.StringWidth(plot[0]), first, "", last)
+	if m.chart.kind == "line" {
+		middle := data.xLabel(n/2, (data.xs[0]+data.xs[n-1])/2)
+		if data.axis == "category" {
+			middle = data.xLabel(n/2, data.xs[n/2])
+		}
+		if n <= 2 {
+			middle = ""
+		}
+		xLabels = spreadLabels(plotWidth, first, middle, last)
+	}
+	lines = append(lines,
+		dim.Render(strings.Repeat(" ", labelWidth+1)+"└"+strings.Repeat("─", plotWidth)),
+		dim.Render(strings.Repeat(" ", labelWidth+2)+xLabels))
+	return strings.Join(lines, "\n")
+}
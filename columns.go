@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+func (m model) updateColumnPicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	order := m.grid.order
+	switch msg.String() {
+	case "esc", "v", "q":
+		m.showColumnPicker = false
+		return m, nil
+	}
+	if len(order) == 0 {
+		return m, nil
+	}
+	switch msg.String() {
+	case "up", "k":
+		m.pickerCursor = max(m.pickerCursor-1, 0)
+	case "down", "j":
+		m.pickerCursor = max(min(m.pickerCursor+1, len(order)-1), 0)
+	case " ", "enter":
+		col := order[m.pickerCursor]
+		if !m.grid.hidden[col] && len(m.grid.visibleColumns()) == 1 {
+			m.queryError = "at least one column must stay visible"
+			break
+		}
+		m.grid.hidden[col] = !m.grid.hidden[col]
+	case "a":
+		m.grid.hidden = map[int]bool{}
+	case "K", "shift+up":
+		if m.pickerCursor > 0 {
+			order[m.pickerCursor-1], order[m.pickerCursor] = order[m.pickerCursor], order[m.pickerCursor-1]
+			m.pickerCursor--
+		}
+	case "J", "shift+down":
+		if m.pickerCursor < len(order)-1 {
+			order[m.pickerCursor+1], order[m.pickerCursor] = order[m.pickerCursor], order[m.pickerCursor+1]
+			m.pickerCursor++
+		}
+	}
+	m.layoutResults()
+	return m, nil
+}
+
+func (m model) columnPickerView() string {
+	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("6")).
+		Render("Columns  (space: show/hide · K/J: move · a: show all · esc: close)")
+
+	height := max(m.RHeight-5, 1)
+	start := 0
+	if m.pickerCursor >= height {
+		start = m.pickerCursor - height + 1
+	}
+
+	lines := []string{title}
+	for i := start; i < len(m.grid.order) && i < start+height; i++ {
+		col := m.grid.order[i]
+		check := "[x]"
+		if m.grid.hidden[col] {
+			check = "[ ]"
+		}
+		line := fmt.Sprintf("%s %s %s", check, m.result.columns[col],
+			lipgloss.NewStyle().Foreground(lipgloss.Color("245")).Render(strings.ToLower(m.result.types[col])))
+		if i == m.pickerCursor {
+			line = lipgloss.NewStyle().Foreground(lipgloss.Color("229")).Background(lipgloss.Color("57")).Render(line)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// toggleFrozenColumns pins every visible column up to and including the
+// cursor, or unpins them if that is already the case.
+func (m *model) toggleFrozenColumns() {
+	if m.grid.frozen == m.colCursor+1 {
+		m.grid.frozen = 0
+	} else {
+		m.grid.frozen = m.colCursor + 1
+	}
+	m.layoutResults()
+}
+
+// pinPrimaryKey moves the primary-key columns of the source table to the
+// front of the grid and freezes them.
+func (m *model) pinPrimaryKey() {
+	if m.result.source == "" {
+		m.queryError = "primary key unknown: the result does not come from a single table"
+		return
+	}
+	keys, err := activeDriver.primaryKey(m.db, m.result.source)
+	if err != nil {
+		m.setQueryError(err)
+		return
+	}
+
+	var pinned, rest []int
+	for _, col := range m.grid.order {
+		if containsString(keys, m.result.columns[col]) {
+			pinned = append(pinned, col)
+		} else {
+			rest = append(rest, col)
+		}
+	}
+	if len(pinned) == 0 {
+		m.queryError = fmt.Sprintf("%s has no primary key in this result", m.result.source)
+		return
+	}
+
+	for _, col := range pinned {
+		delete(m.grid.hidden, col)
+	}
+	m.grid.order = append(pinned, rest...)
+	m.grid.frozen = len(pinned)
+	m.colCursor = 0
+	m.layoutResults()
+}
@@ -0,0 +1,283 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/joho/godotenv"
+)
+
+// dbDriver is what the explorer needs from a database engine. Features
+// built on Postgres catalogs (activity, locks, LISTEN, …) are not part of it
+// and check requirePostgres instead.
+type dbDriver interface {
+	name() string
+	// connect opens the pool; onNotice receives server notices where the
+	// engine has them.
+	connect(dsn string, onNotice func(serverNotice)) (*sql.DB, error)
+	tables(db *sql.DB) ([]dbItem, error)
+	columns(db *sql.DB, schema, table string) ([]dbItem, error)
+	// primaryKey takes the table as SQL text, e.g. "public"."users".
+	primaryKey(db *sql.DB, table string) ([]string, error)
+	quoteIdentifier(name string) string
+	quoteLiteral(value string) string
+	// placeholder is the bind parameter for the nth argument, from 1.
+	placeholder(n int) string
+	// objects lists the tables, views, columns and functions the object
+	// finder searches.
+	objects(db *sql.DB) ([]catalogObject, error)
+}
+
+// ddlDriver is implemented by engines that can print a table's CREATE
+// statement.
+type ddlDriver interface {
+	createStatement(db *sql.DB, schema, table string) (string, error)
+}
+
+// routineDriver is implemented by engines with stored functions.
+type routineDriver interface {
+	routineDefinition(db *sql.DB, routine catalogObject) (string, error)
+}
+
+// databaseDriver is implemented by engines whose list starts with the
+// databases of the server rather than with the tables.
+type databaseDriver interface {
+	databases(db *sql.DB) ([]dbItem, error)
+	databaseTables(db *sql.DB, database string) ([]dbItem, error)
+}
+
+// databaseSwitcher is implemented by engines whose connections are bound to
+// one database, so reaching another one takes a new pool.
+type databaseSwitcher interface {
+	currentDatabase(db *sql.DB) (string, error)
+	// databaseDSN returns dsn with the database replaced.
+	databaseDSN(dsn, database string) (string, error)
+}
+
+// activeDriver is the driver of the open connection, activeDSN the DSN it
+// was opened with.
+var (
+	activeDriver dbDriver = postgresDriver{}
+	activeDSN    string
+)
+
+// driverForURL picks the driver from the scheme of a connection URL and
+// returns the DSN to hand it.
+func driverForURL(url string) (dbDriver, string, error) {
+	switch {
+	case strings.HasPrefix(url, "postgres://"), strings.HasPrefix(url, "postgresql://"):
+		return postgresDriver{}, url, nil
+	case strings.HasPrefix(url, "sqlite://"):
+		return sqliteDriver{}, strings.TrimPrefix(url, "sqlite://"), nil
+	case strings.HasPrefix(url, "sqlite:"):
+		return sqliteDriver{}, strings.TrimPrefix(url, "sqlite:"), nil
+	case strings.HasPrefix(url, "file:"):
+		return sqliteDriver{}, url, nil
+	case strings.HasPrefix(url, "mysql://"):
+		dsn, err := mysqlDSN(url)
+		return mysqlDriver{}, dsn, err
+	}
+	return nil, "", fmt.Errorf("unsupported DATABASE_URL %q: use postgres://, mysql://, sqlite:// or file:", url)
+}
+
+// openDatabase connects to DATABASE_URL, or to Postgres with the PG_*
+// settings when it is not set.
+func openDatabase(onNotice func(serverNotice)) (*sql.DB, error) {
+	if err := godotenv.Load(); err != nil {
+		log.Println("Failed to load .env file, using environment values")
+	}
+
+	driver, dsn := dbDriver(postgresDriver{}), postgresDSN()
+	if url := os.Getenv("DATABASE_URL"); url != "" {
+		var err error
+		if driver, dsn, err = driverForURL(url); err != nil {
+			return nil, err
+		}
+	}
+
+	db, err := driver.connect(dsn, onNotice)
+	if err != nil {
+		return nil, err
+	}
+	activeDriver, activeDSN = driver, dsn
+	fmt.Printf("✅ Successfully connected to %s\n", driver.name())
+	return db, nil
+}
+
+// requirePostgres reports whether the connection is to Postgres, and
+// otherwise explains that feature is not available.
+func (m *model) requirePostgres(feature string) bool {
+	if _, ok := activeDriver.(postgresDriver); ok {
+		return true
+	}
+	m.queryError = fmt.Sprintf("%s needs PostgreSQL; connected to %s", feature, activeDriver.name())
+	return false
+}
+
+// showCreateStatement adds the table's CREATE statement to the editor.
+func (m *model) showCreateStatement(schema, table string) {
+	driver, ok := activeDriver.(ddlDriver)
+	if !ok {
+		m.queryError = fmt.Sprintf("Showing a CREATE statement is not supported on %s", activeDriver.name())
+		return
+	}
+	statement, err := driver.createStatement(m.db, schema, table)
+	if err != nil {
+		m.setQueryError(err)
+		return
+	}
+	m.appendDefinition(statement)
+}
+
+// appendDefinition adds a CREATE statement to the editor and focuses it.
+func (m *model) appendDefinition(statement string) {
+	appendStatement(&m.editor, strings.TrimSuffix(strings.TrimSpace(statement), ";")+";")
+	m.focusState = focusEditor
+	m.dbList.SetFilteringEnabled(false)
+	m.editor.Focus()
+}
+
+// getRootItems is the top level of the database list: the server
+// categories on Postgres, then the databases on engines that list them,
+// otherwise the tables.
+func getRootItems(db *sql.DB) ([]dbItem, error) {
+	var items []dbItem
+	if _, ok := activeDriver.(postgresDriver); ok {
+		items = []dbItem{
+			{name: "Server settings", kind: "settings", detail: "pg_settings"},
+			{name: "Roles", kind: "roles", detail: "memberships, attributes and grants"},
+		}
+	}
+
+	var children []dbItem
+	var err error
+	if driver, ok := activeDriver.(databaseDriver); ok {
+		children, err = driver.databases(db)
+	} else {
+		children, err = activeDriver.tables(db)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return append(items, children...), nil
+}
+
+// prepareDatabase connects to database before its tables are listed, on
+// engines that need a pool per database.
+func (m *model) prepareDatabase(name string) error {
+	switcher, ok := activeDriver.(databaseSwitcher)
+	if !ok {
+		return nil
+	}
+	current, err := switcher.currentDatabase(m.db)
+	if err != nil || name == current {
+		return err
+	}
+	return m.switchDatabase(switcher, name)
+}
+
+// switchDatabase replaces the pool with one on database, using the same
+// credentials. What belongs to the old database is dropped: the results,
+// a running watch and the LISTEN connection.
+func (m *model) switchDatabase(switcher databaseSwitcher, database string) error {
+	// Closing the pool waits for its queries, so the UI would hang until a
+	// long job is done, and the job would lose its connection.
+	if job := m.runningJob(); job != "" {
+		return fmt.Errorf("cannot switch databases while %s is running", job)
+	}
+	dsn, err := switcher.databaseDSN(activeDSN, database)
+	if err != nil {
+		return err
+	}
+	db, err := activeDriver.connect(dsn, noticeHandler(m.noticeCh, m.noticeDrops))
+	if err != nil {
+		return err
+	}
+	m.db.Close()
+	m.db, activeDSN = db, dsn
+	m.metadata.reset()
+
+	if m.notify.listener != nil {
+		m.notify.listener.Close()
+	}
+	m.notify = newNotifyMonitor()
+	m.stopWatch()
+	m.browsing = false
+	m.trail = nil
+	m.result = resultSet{}
+	m.showResults = false
+	m.statusMessage = "Connected to " + database
+	return nil
+}
+
+// runningJob names the background work that uses the pool, if any.
+func (m model) runningJob() string {
+	switch {
+	case m.health.job != nil:
+		return m.health.job.statement
+	case m.importer.step == importStepRunning:
+		return "a CSV import"
+	case m.profiler.running:
+		return "a column profile"
+	}
+	return ""
+}
+
+func columnItem(schema, table, column, dataType string) dbItem {
+	return dbItem{
+		name:     column + "\n" + fmt.Sprintf("(%s)", dataType),
+		kind:     "column",
+		schema:   schema,
+		table:    table,
+		column:   column,
+		dataType: dataType,
+	}
+}
+
+func runQuery(db *sql.DB, query string, args ...interface{}) (resultSet, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return resultSet{}, err
+	}
+	defer rows.Close()
+
+	rs, err := scanResultSet(rows)
+	return splitPlanLines(rs), err
+}
+
+// splitPlanLines puts a plan printed as one text value, like MySQL's
+// EXPLAIN FORMAT=TREE, on one row per line so it reads in the grid.
+func splitPlanLines(rs resultSet) resultSet {
+	if len(rs.columns) != 1 || rs.columns[0] != "EXPLAIN" {
+		return rs
+	}
+	var rows []table.Row
+	var nulls [][]bool
+	for _, row := range rs.rows {
+		for _, line := range strings.Split(row[0], "\n") {
+			rows = append(rows, table.Row{line})
+			nulls = append(nulls, []bool{false})
+		}
+	}
+	rs.rows, rs.nulls = rows, nulls
+	return rs
+}
+
+func qualifiedName(schema, name string) string {
+	if schema == "" {
+		return activeDriver.quoteIdentifier(name)
+	}
+	return activeDriver.quoteIdentifier(schema) + "." + activeDriver.quoteIdentifier(name)
+}
+
+func quoteIdentifiers(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = activeDriver.quoteIdentifier(name)
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,404 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// editSession collects pending changes to a result that maps to a single
+// table with a primary key. Edited values are written into the result so the
+// grid shows them; the original rows are kept to build WHERE clauses.
+type editSession struct {
+	active        bool
+	primaryKey    []string
+	keyColumns    []int
+	original      map[int]table.Row
+	originalNulls map[int][]bool
+	edited        map[int]map[int]bool
+	inserted      map[int]bool
+	deleted       map[int]bool
+
+	input   textinput.Model
+	editing bool
+	typed   bool // the input was changed since it opened
+	editRow int
+	editCol int
+}
+
+func (e editSession) pending() int {
+	count := len(e.inserted) + len(e.deleted)
+	for row := range e.edited {
+		if !e.inserted[row] && !e.deleted[row] {
+			count++
+		}
+	}
+	return count
+}
+
+func (e editSession) rowMarker(row int) string {
+	switch {
+	case e.deleted[row]:
+		return "✖"
+	case e.inserted[row]:
+		return "✚"
+	case len(e.edited[row]) > 0:
+		return "✎"
+	}
+	return " "
+}
+
+// startEditing checks that the result can be written back and opens a
+// session if none is running yet.
+func (m *model) startEditing() bool {
+	if m.edits.active {
+		return true
+	}
+	if m.result.source == "" {
+		m.queryError = "read-only result: it does not come from a single table"
+		return false
+	}
+	primaryKey, err := activeDriver.primaryKey(m.db, m.result.source)
+	if err != nil {
+		m.setQueryError(err)
+		return false
+	}
+	if len(primaryKey) == 0 {
+		m.queryError = fmt.Sprintf("read-only result: %s has no primary key", m.result.source)
+		return false
+	}
+
+	var keyColumns []int
+	for _, key := range primaryKey {
+		found := false
+		for col, name := range m.result.columns {
+			if name == key {
+				keyColumns = append(keyColumns, col)
+				found = true
+				break
+			}
+		}
+		if !found {
+			m.queryError = fmt.Sprintf("read-only result: primary key column %s is not selected", key)
+			return false
+		}
+	}
+
+	input := textinput.New()
+	input.CharLimit = 0
+	m.edits = editSession{
+		active:        true,
+		primaryKey:    primaryKey,
+		keyColumns:    keyColumns,
+		original:      map[int]table.Row{},
+		originalNulls: map[int][]bool{},
+		edited:        map[int]map[int]bool{},
+		inserted:      map[int]bool{},
+		deleted:       map[int]bool{},
+		input:         input,
+	}
+	return true
+}
+
+func (m *model) beginCellEdit() tea.Cmd {
+	row, col := m.resultsTable.Cursor(), m.selectedColumn()
+	if row >= len(m.result.rows) || col < 0 || !m.startEditing() {
+		return nil
+	}
+	if m.edits.deleted[row] {
+		m.queryError = "row is marked for deletion"
+		return nil
+	}
+
+	m.edits.editing = true
+	m.edits.typed = false
+	m.edits.editRow, m.edits.editCol = row, col
+	m.edits.input.Prompt = m.result.columns[col] + " = "
+	value := m.result.rows[row][col]
+	if m.result.nulls[row][col] {
+		value = ""
+	}
+	m.edits.input.SetValue(value)
+	m.edits.input.CursorEnd()
+	return m.edits.input.Focus()
+}
+
+func (m *model) setCell(row, col int, value string, null bool) {
+	if !m.edits.inserted[row] {
+		if _, ok := m.edits.original[row]; !ok {
+			m.edits.original[row] = append(table.Row{}, m.result.rows[row]...)
+			m.edits.originalNulls[row] = append([]bool{}, m.result.nulls[row]...)
+		}
+	}
+	if value == m.result.rows[row][col] && null == m.result.nulls[row][col] {
+		return
+	}
+
+	m.result.rows[row][col] = value
+	m.result.nulls[row][col] = null
+	if m.edits.edited[row] == nil {
+		m.edits.edited[row] = map[int]bool{}
+	}
+	m.edits.edited[row][col] = true
+	m.layoutResults()
+}
+
+func (m *model) setCellNull() {
+	row, col := m.resultsTable.Cursor(), m.selectedColumn()
+	if row >= len(m.result.rows) || col < 0 || !m.startEditing() {
+		return
+	}
+	m.setCell(row, col, "NULL", true)
+}
+
+func (m *model) insertRow() {
+	if len(m.result.rows) >= maxRowsToRender {
+		m.queryError = fmt.Sprintf("Only the first %d rows are shown; narrow the query to add a row", maxRowsToRender)
+		return
+	}
+	if !m.startEditing() {
+		return
+	}
+	row := make(table.Row, len(m.result.columns))
+	nulls := make([]bool, len(m.result.columns))
+	for i := range row {
+		row[i] = "DEFAULT"
+	}
+	m.result.rows = append(m.result.rows, row)
+	m.result.nulls = append(m.result.nulls, nulls)
+	m.edits.inserted[len(m.result.rows)-1] = true
+	m.layoutResults()
+	m.resultsTable.GotoBottom()
+}
+
+func (m *model) toggleDeleteRow() {
+	row := m.resultsTable.Cursor()
+	if row >= len(m.result.rows) || !m.startEditing() {
+		return
+	}
+	if m.edits.inserted[row] {
+		m.queryError = "new rows can't be deleted; discard the changes instead"
+		return
+	}
+	if m.edits.deleted[row] {
+		delete(m.edits.deleted, row)
+	} else {
+		m.edits.deleted[row] = true
+	}
+	m.layoutResults()
+}
+
+// discardEdits restores the rows as they were read from the database.
+func (m *model) discardEdits() {
+	for row, original := range m.edits.original {
+		m.result.rows[row] = original
+		m.result.nulls[row] = m.edits.originalNulls[row]
+	}
+	kept := len(m.result.rows) - len(m.edits.inserted)
+	m.result.rows = m.result.rows[:kept]
+	m.result.nulls = m.result.nulls[:kept]
+	m.edits = editSession{}
+	m.showReview = false
+	m.layoutResults()
+}
+
+// keyCondition matches row by its primary key, once as text for the review
+// and once with placeholders for the scanned key values, since the text of
+// types like bytea or timestamptz doesn't round-trip.
+func (m model) keyCondition(row int) (string, string, []interface{}) {
+	values, nulls := m.result.rows[row], m.result.nulls[row]
+	if original, ok := m.edits.original[row]; ok {
+		values, nulls = original, m.edits.originalNulls[row]
+	}
+
+	conditions := make([]string, len(m.edits.keyColumns))
+	placeholders := make([]string, len(m.edits.keyColumns))
+	for i, col := range m.edits.keyColumns {
+		column := activeDriver.quoteIdentifier(m.result.columns[col])
+		conditions[i] = column + " = " + sqlLiteral(values[col], nulls[col])
+		placeholders[i] = column + " = " + activeDriver.placeholder(i+1)
+	}
+	text := strings.Join(conditions, " AND ")
+	args, ok := m.result.keyValues(row, m.edits.primaryKey)
+	if !ok {
+		return text, text, nil
+	}
+	return text, strings.Join(placeholders, " AND "), args
+}
+
+func sqlLiteral(value string, null bool) string {
+	if null {
+		return "NULL"
+	}
+	return activeDriver.quoteLiteral(value)
+}
+
+// editStatement is a pending change as shown in the review and as run.
+type editStatement struct {
+	text  string
+	query string
+	args  []interface{}
+}
+
+// editStatements turns the pending changes into the statements that will be
+// run, deletes first so a re-inserted key doesn't collide.
+func (m model) editStatements() []editStatement {
+	var statements []editStatement
+	source := m.result.source
+
+	for _, row := range sortedKeys(m.edits.deleted) {
+		text, query, args := m.keyCondition(row)
+		statements = append(statements, editStatement{
+			text:  fmt.Sprintf("DELETE FROM %s WHERE %s;", source, text),
+			query: fmt.Sprintf("DELETE FROM %s WHERE %s", source, query),
+			args:  args,
+		})
+	}
+
+	for _, row := range sortedKeys(m.edits.edited) {
+		if m.edits.deleted[row] || m.edits.inserted[row] {
+			continue
+		}
+		var assignments []string
+		for _, col := range sortedKeys(m.edits.edited[row]) {
+			assignments = append(assignments, activeDriver.quoteIdentifier(m.result.columns[col])+" = "+
+				sqlLiteral(m.result.rows[row][col], m.result.nulls[row][col]))
+		}
+		text, query, args := m.keyCondition(row)
+		set := strings.Join(assignments, ", ")
+		statements = append(statements, editStatement{
+			text:  fmt.Sprintf("UPDATE %s SET %s WHERE %s;", source, set, text),
+			query: fmt.Sprintf("UPDATE %s SET %s WHERE %s", source, set, query),
+			args:  args,
+		})
+	}
+
+	for _, row := range sortedKeys(m.edits.inserted) {
+		var columns, values []string
+		for _, col := range sortedKeys(m.edits.edited[row]) {
+			columns = append(columns, activeDriver.quoteIdentifier(m.result.columns[col]))
+			values = append(values, sqlLiteral(m.result.rows[row][col], m.result.nulls[row][col]))
+		}
+		query := fmt.Sprintf("INSERT INTO %s DEFAULT VALUES", source)
+		if len(columns) > 0 {
+			query = fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
+				source, strings.Join(columns, ", "), strings.Join(values, ", "))
+		}
+		statements = append(statements, editStatement{text: query + ";", query: query})
+	}
+
+	return statements
+}
+
+func sortedKeys[V any](values map[int]V) []int {
+	keys := make([]int, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Ints(keys)
+	return keys
+}
+
+func (m *model) openReview() {
+	if m.edits.pending() == 0 {
+		m.statusMessage = "No pending changes"
+		return
+	}
+	var texts []string
+	for _, statement := range m.editStatements() {
+		texts = append(texts, statement.text)
+	}
+	m.review = newSQLViewer("Pending changes (ctrl+s: apply in one transaction · y: copy · esc: back)",
+		texts, m.TotalWidth-6, m.RHeight-5)
+	m.showReview = true
+}
+
+// applyEdits runs every statement in one transaction. UPDATE and DELETE must
+// hit exactly one row, otherwise the row changed underneath us and nothing is
+// committed.
+func (m *model) applyEdits() {
+	statements := m.editStatements()
+	tx, err := m.db.Begin()
+	if err != nil {
+		m.setQueryError(err)
+		return
+	}
+
+	for _, statement := range statements {
+		res, err := tx.Exec(statement.query, statement.args...)
+		if err != nil {
+			tx.Rollback()
+			m.setQueryError(err)
+			return
+		}
+		if strings.HasPrefix(statement.query, "INSERT") {
+			continue
+		}
+		if affected, err := res.RowsAffected(); err == nil && affected != 1 {
+			tx.Rollback()
+			m.queryError = fmt.Sprintf("rolled back: %d rows matched %q", affected, statement.text)
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		m.setQueryError(err)
+		return
+	}
+
+	m.edits = editSession{}
+	m.showReview = false
+	m.reloadResults()
+	m.statusMessage = fmt.Sprintf("Applied %d statements", len(statements))
+}
+
+func (m model) updateCellEdit(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		m.edits.editing = false
+		m.edits.input.Blur()
+		row, col, value := m.edits.editRow, m.edits.editCol, m.edits.input.Value()
+		// A NULL opens as an empty input; leaving it untouched keeps the
+		// NULL, while clearing typed text stores an empty string.
+		if !m.edits.typed && m.result.nulls[row][col] {
+			break
+		}
+		m.setCell(row, col, value, false)
+	case "esc":
+		m.edits.editing = false
+		m.edits.input.Blur()
+	default:
+		var cmd tea.Cmd
+		before := m.edits.input.Value()
+		m.edits.input, cmd = m.edits.input.Update(msg)
+		if m.edits.input.Value() != before {
+			m.edits.typed = true
+		}
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m model) updateReview(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		m.showReview = false
+	case "up", "k":
+		m.review.moveCursor(-1)
+	case "down", "j":
+		m.review.moveCursor(1)
+	case "pgup", "b":
+		m.review.moveCursor(-m.review.viewport.Height)
+	case "pgdown", "f":
+		m.review.moveCursor(m.review.viewport.Height)
+	case "y":
+		m.copyToClipboard(m.review.raw, "statements")
+	case "ctrl+s":
+		m.applyEdits()
+	}
+	return m, nil
+}
@@ -0,0 +1,722 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lib/pq"
+)
+
+// erColumnLimit is how many columns a box shows besides the key columns.
+const erColumnLimit = 12
+
+type erColumn struct {
+	name     string
+	dataType string
+	primary  bool
+	foreign  bool
+}
+
+type erTable struct {
+	schema  string
+	name    string
+	columns []erColumn
+}
+
+type erKey struct{ schema, name string }
+
+type erForeignKey struct {
+	name          string
+	child, parent erKey
+	columns       []string
+	refColumns    []string
+}
+
+type erEdge struct {
+	key      erForeignKey
+	from, to int // child and parent, as indexes into the tables
+}
+
+// erBox is where a table is drawn; rows are the column lines it shows.
+type erBox struct {
+	x, y, width int
+	rows        []string
+	rowOf       map[string]int // column name to row; hidden ones map to the "… more" row
+}
+
+func (b erBox) height() int { return len(b.rows) + 2 }
+
+type erStyle uint8
+
+const (
+	erText erStyle = iota
+	erBorder
+	erLine
+	erTitle
+	erFocus
+	erKeyMark
+)
+
+// erDiagram draws the tables of a schema, or those within a few foreign
+// key hops of one table, as boxes: parents left of the tables that refer
+// to them, with a connector from each foreign key to the key it references.
+type erDiagram struct {
+	schema string
+	table  string // table in the center; "" shows the whole schema
+	focus  string // table the diagram was opened on
+	hops   int
+	tables []erTable
+	edges  []erEdge
+	canvas [][]rune
+	styles [][]erStyle
+	x, y   int // top-left corner of the visible part
+}
+
+const erForeignKeysQuery = `
+SELECT c.conname, ns.nspname, cl.relname, nr.nspname, cr.relname,
+       array_agg(a.attname ORDER BY k.ord), array_agg(af.attname ORDER BY k.ord)
+FROM pg_constraint c
+JOIN pg_class cl ON cl.oid = c.conrelid
+JOIN pg_namespace ns ON ns.oid = cl.relnamespace
+JOIN pg_class cr ON cr.oid = c.confrelid
+JOIN pg_namespace nr ON nr.oid = cr.relnamespace
+CROSS JOIN LATERAL unnest(c.conkey, c.confkey) WITH ORDINALITY AS k(attnum, fattnum, ord)
+JOIN pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = k.attnum
+JOIN pg_attribute af ON af.attrelid = c.confrelid AND af.attnum = k.fattnum
+WHERE c.contype = 'f' AND c.conparentid = 0
+  AND ns.nspname NOT IN ('pg_catalog', 'information_schema')
+GROUP BY c.oid, c.conname, ns.nspname, cl.relname, nr.nspname, cr.relname
+ORDER BY 2, 3, 1`
+
+// getERForeignKeys reads the foreign keys of the whole database, leaving
+// out the copies Postgres keeps on partitions.
+func getERForeignKeys(db *sql.DB) ([]erForeignKey, error) {
+	rows, err := db.Query(erForeignKeysQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []erForeignKey
+	for rows.Next() {
+		var k erForeignKey
+		if err := rows.Scan(&k.name, &k.child.schema, &k.child.name, &k.parent.schema, &k.parent.name,
+			pq.Array(&k.columns), pq.Array(&k.refColumns)); err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	return keys, rows.Err()
+}
+
+// getERTables reads the columns and primary keys of the tables matching
+// condition, which uses $1.
+func getERTables(db *sql.DB, condition string, arg interface{}) ([]erTable, error) {
+	rows, err := db.Query(`
+SELECT n.nspname, c.relname, a.attname, format_type(a.atttypid, a.atttypmod), coalesce(a.attnum = ANY(i.indkey), false)
+FROM pg_class c
+JOIN pg_namespace n ON n.oid = c.relnamespace
+JOIN pg_attribute a ON a.attrelid = c.oid AND a.attnum > 0 AND NOT a.attisdropped
+LEFT JOIN pg_index i ON i.indrelid = c.oid AND i.indisprimary
+WHERE `+condition+`
+ORDER BY n.nspname, c.relname, a.attnum`, arg)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tables []erTable
+	for rows.Next() {
+		var schema, table string
+		var column erColumn
+		if err := rows.Scan(&schema, &table, &column.name, &column.dataType, &column.primary); err != nil {
+			return nil, err
+		}
+		if n := len(tables); n == 0 || tables[n-1].schema != schema || tables[n-1].name != table {
+			tables = append(tables, erTable{schema: schema, name: table})
+		}
+		tables[len(tables)-1].columns = append(tables[len(tables)-1].columns, column)
+	}
+	return tables, rows.Err()
+}
+
+// erNeighbours returns start and the tables reachable from it through at
+// most hops foreign keys, followed either way.
+func erNeighbours(keys []erForeignKey, start erKey, hops int) []erKey {
+	seen := map[erKey]bool{start: true}
+	found := []erKey{start}
+	frontier := map[erKey]bool{start: true}
+	for hop := 0; hop < hops && len(frontier) > 0; hop++ {
+		next := map[erKey]bool{}
+		for _, k := range keys {
+			for _, pair := range [][2]erKey{{k.child, k.parent}, {k.parent, k.child}} {
+				if frontier[pair[0]] && !seen[pair[1]] {
+					seen[pair[1]] = true
+					next[pair[1]] = true
+					found = append(found, pair[1])
+				}
+			}
+		}
+		frontier = next
+	}
+	return found
+}
+
+func (m *model) openERDiagram(schema, table string) {
+	if !m.requirePostgres("The ER diagram") {
+		return
+	}
+	m.er = erDiagram{schema: schema, table: table, focus: table, hops: 1}
+	if err := m.er.load(m.db); err != nil {
+		m.setQueryError(err)
+		return
+	}
+	m.showER = true
+	m.editor.Blur()
+}
+
+func (m *model) closeERDiagram() {
+	m.showER = false
+	m.focusState = focusEditor
+	m.dbList.SetFilteringEnabled(false)
+	m.editor.Focus()
+}
+
+func (d *erDiagram) load(db *sql.DB) error {
+	keys, err := getERForeignKeys(db)
+	if err != nil {
+		return err
+	}
+	if d.table == "" {
+		d.tables, err = getERTables(db, "n.nspname = $1 AND c.relkind IN ('r', 'p') AND NOT c.relispartition", d.schema)
+	} else {
+		var names []string
+		for _, k := range erNeighbours(keys, erKey{d.schema, d.table}, d.hops) {
+			names = append(names, qualifiedName(k.schema, k.name))
+		}
+		d.tables, err = getERTables(db, "c.oid = ANY($1::regclass[])", pq.Array(names))
+	}
+	if err != nil {
+		return err
+	}
+
+	index := map[erKey]int{}
+	for i, t := range d.tables {
+		index[erKey{t.schema, t.name}] = i
+	}
+	d.edges = nil
+	for _, k := range keys {
+		from, childFound := index[k.child]
+		to, parentFound := index[k.parent]
+		if !childFound || !parentFound {
+			continue
+		}
+		d.edges = append(d.edges, erEdge{key: k, from: from, to: to})
+		for i, column := range d.tables[from].columns {
+			if containsString(k.columns, column.name) {
+				d.tables[from].columns[i].foreign = true
+			}
+		}
+	}
+	d.layout()
+	d.x, d.y = 0, 0
+	return nil
+}
+
+// label is the table's name, qualified when it is not in the diagram's schema.
+func (d erDiagram) label(t erTable) string {
+	if t.schema == d.schema {
+		return t.name
+	}
+	return t.schema + "." + t.name
+}
+
+func (c erColumn) keys() string {
+	var keys []string
+	if c.primary {
+		keys = append(keys, "PK")
+	}
+	if c.foreign {
+		keys = append(keys, "FK")
+	}
+	return strings.Join(keys, ",")
+}
+
+// box lays out the lines of a table: all key columns and the first others
+// up to erColumnLimit.
+func (d erDiagram) box(t erTable) erBox {
+	var shown []erColumn
+	others, hidden := 0, 0
+	for _, column := range t.columns {
+		switch {
+		case column.primary || column.foreign:
+			shown = append(shown, column)
+		case others < erColumnLimit:
+			shown = append(shown, column)
+			others++
+		default:
+			hidden++
+		}
+	}
+
+	nameWidth, typeWidth, keyWidth := 0, 0, 0
+	for _, column := range shown {
+		nameWidth = max(nameWidth, len([]rune(column.name)))
+		typeWidth = max(typeWidth, min(len([]rune(column.dataType)), 20))
+		keyWidth = max(keyWidth, len(column.keys()))
+	}
+	pad := func(s string, width int) string {
+		runes := []rune(s)
+		if len(runes) > width {
+			return string(runes[:width-1]) + "…"
+		}
+		return s + strings.Repeat(" ", width-len(runes))
+	}
+
+	b := erBox{rowOf: map[string]int{}}
+	for _, column := range shown {
+		b.rowOf[column.name] = len(b.rows)
+		b.rows = append(b.rows, " "+pad(column.name, nameWidth)+"  "+pad(column.dataType, typeWidth)+"  "+pad(column.keys(), keyWidth)+" ")
+	}
+	if hidden > 0 {
+		for _, column := range t.columns {
+			if _, ok := b.rowOf[column.name]; !ok {
+				b.rowOf[column.name] = len(b.rows)
+			}
+		}
+		b.rows = append(b.rows, fmt.Sprintf(" … %d more", hidden))
+	}
+	b.width = len([]rune(d.label(t))) + 6
+	for _, row := range b.rows {
+		b.width = max(b.width, len([]rune(row))+2)
+	}
+	return b
+}
+
+// layout places the boxes in columns by how long a chain of foreign keys
+// leads from them to a table that references nothing, then draws the
+// connectors and the boxes into the canvas.
+func (d *erDiagram) layout() {
+	n := len(d.tables)
+	layer := make([]int, n)
+	for pass := 0; pass < n; pass++ {
+		changed := false
+		for _, e := range d.edges {
+			if e.from != e.to && layer[e.from] < layer[e.to]+1 && layer[e.to]+1 < n {
+				layer[e.from] = layer[e.to] + 1
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+
+	layers := 0
+	for _, l := range layer {
+		layers = max(layers, l+1)
+	}
+	columns := make([][]int, layers)
+	for i := range d.tables {
+		columns[layer[i]] = append(columns[layer[i]], i)
+	}
+	// Order each column by where the parents of its tables are.
+	position := make([]float64, n)
+	for l, column := range columns {
+		if l > 0 {
+			for _, i := range column {
+				sum, count := 0.0, 0
+				for _, e := range d.edges {
+					if e.from == i && layer[e.to] < l {
+						sum += position[e.to]
+						count++
+					}
+				}
+				if count > 0 {
+					position[i] = sum / float64(count)
+				}
+			}
+			sort.SliceStable(column, func(a, b int) bool { return position[column[a]] < position[column[b]] })
+		}
+		for rank, i := range column {
+			position[i] = float64(rank)
+		}
+	}
+
+	// Every forward connector gets a lane of its own in the gutter left of
+	// its child's column; the others go around the right of their boxes.
+	lanes := make([]int, layers)
+	for _, e := range d.edges {
+		if layer[e.from] > layer[e.to] {
+			lanes[layer[e.from]]++
+		}
+	}
+	boxes := make([]erBox, n)
+	x, width, height := 0, 0, 0
+	columnX := make([]int, layers)
+	for l, column := range columns {
+		if l > 0 {
+			x += 4 + 2*min(lanes[l], 10)
+		}
+		columnX[l] = x
+		y, columnWidth := 0, 0
+		for _, i := range column {
+			boxes[i] = d.box(d.tables[i])
+			boxes[i].x, boxes[i].y = x, y
+			y += boxes[i].height() + 1
+			columnWidth = max(columnWidth, boxes[i].width)
+		}
+		x += columnWidth
+		height = max(height, y)
+	}
+	width = x + 12
+
+	c := newERCanvas(width, height)
+	lane := make([]int, layers)
+	back := 0
+	type mark struct {
+		x, y int
+		r    rune
+	}
+	var marks []mark
+	for _, e := range d.edges {
+		parent, child := boxes[e.to], boxes[e.from]
+		ys := parent.y + 1 + parent.rowOf[e.key.refColumns[0]]
+		ye := child.y + 1 + child.rowOf[e.key.columns[0]]
+		xs := parent.x + parent.width
+		if l := layer[e.from]; l > layer[e.to] {
+			laneX := columnX[l] - 2 - 2*(lane[l]%10)
+			lane[l]++
+			c.hline(xs, laneX, ys)
+			c.vline(laneX, ys, ye)
+			c.hline(laneX, child.x-1, ye)
+			marks = append(marks, mark{child.x, ye, '┤'})
+		} else {
+			laneX := max(xs, child.x+child.width) + 1 + 2*(back%4)
+			back++
+			c.hline(xs, laneX, ys)
+			c.vline(laneX, ys, ye)
+			c.hline(child.x+child.width, laneX, ye)
+			marks = append(marks, mark{child.x + child.width - 1, ye, '├'})
+		}
+		marks = append(marks, mark{xs - 1, ys, '├'}, mark{xs, ys, '◀'})
+	}
+	c.resolveLines()
+
+	for i, b := range boxes {
+		style := erTitle
+		if d.tables[i].schema == d.schema && d.tables[i].name == d.focus {
+			style = erFocus
+		}
+		c.drawBox(b, d.label(d.tables[i]), style)
+	}
+	for _, mk := range marks {
+		if mk.r == '◀' {
+			c.put(mk.x, mk.y, mk.r, erLine)
+		} else {
+			c.put(mk.x, mk.y, mk.r, erBorder)
+		}
+	}
+	d.canvas, d.styles = c.runes, c.styles
+}
+
+// erCanvas collects connectors as the directions leaving each cell, so
+// crossings and corners join into the right box-drawing character.
+type erCanvas struct {
+	runes  [][]rune
+	styles [][]erStyle
+	lines  [][]uint8
+}
+
+const (
+	erUp uint8 = 1 << iota
+	erDown
+	erLeft
+	erRight
+)
+
+func newERCanvas(width, height int) erCanvas {
+	var c erCanvas
+	for y := 0; y < height; y++ {
+		c.runes = append(c.runes, []rune(strings.Repeat(" ", width)))
+		c.styles = append(c.styles, make([]erStyle, width))
+		c.lines = append(c.lines, make([]uint8, width))
+	}
+	return c
+}
+
+func (c *erCanvas) link(x, y int, direction uint8) {
+	if y >= 0 && y < len(c.lines) && x >= 0 && x < len(c.lines[y]) {
+		c.lines[y][x] |= direction
+	}
+}
+
+func (c *erCanvas) hline(x0, x1, y int) {
+	if x0 > x1 {
+		x0, x1 = x1, x0
+	}
+	for x := x0; x <= x1; x++ {
+		if x > x0 {
+			c.link(x, y, erLeft)
+		}
+		if x < x1 {
+			c.link(x, y, erRight)
+		}
+	}
+}
+
+func (c *erCanvas) vline(x, y0, y1 int) {
+	if y0 > y1 {
+		y0, y1 = y1, y0
+	}
+	for y := y0; y <= y1; y++ {
+		if y > y0 {
+			c.link(x, y, erUp)
+		}
+		if y < y1 {
+			c.link(x, y, erDown)
+		}
+	}
+}
+
+var erLineRunes = map[uint8]rune{
+	erLeft: '─', erRight: '─', erLeft | erRight: '─',
+	erUp: '│', erDown: '│', erUp | erDown: '│',
+	erDown | erRight: '┌', erDown | erLeft: '┐', erUp | erRight: '└', erUp | erLeft: '┘',
+	erUp | erDown | erRight: '├', erUp | erDown | erLeft: '┤',
+	erLeft | erRight | erDown: '┬', erLeft | erRight | erUp: '┴',
+	erUp | erDown | erLeft | erRight: '┼',
+}
+
+func (c *erCanvas) resolveLines() {
+	for y, row := range c.lines {
+		for x, directions := range row {
+			if directions != 0 {
+				c.put(x, y, erLineRunes[directions], erLine)
+			}
+		}
+	}
+}
+
+func (c *erCanvas) put(x, y int, r rune, style erStyle) {
+	if y >= 0 && y < len(c.runes) && x >= 0 && x < len(c.runes[y]) {
+		c.runes[y][x] = r
+		c.styles[y][x] = style
+	}
+}
+
+func (c *erCanvas) text(x, y int, s string, style erStyle) {
+	for i, r := range []rune(s) {
+		c.put(x+i, y, r, style)
+	}
+}
+
+func (c *erCanvas) drawBox(b erBox, title string, titleStyle erStyle) {
+	inner := b.width - 2
+	c.text(b.x, b.y, "┌─ ", erBorder)
+	c.text(b.x+3, b.y, title, titleStyle)
+	c.text(b.x+3+len([]rune(title)), b.y, " "+strings.Repeat("─", max(inner-len([]rune(title))-3, 0))+"┐", erBorder)
+	for i, row := range b.rows {
+		y := b.y + 1 + i
+		c.put(b.x, y, '│', erBorder)
+		c.text(b.x+1, y, row+strings.Repeat(" ", inner-len([]rune(row))), erText)
+		if keys := []rune(strings.TrimRight(row, " ")); strings.HasSuffix(string(keys), "PK") || strings.HasSuffix(string(keys), "FK") {
+			for x := len(keys) - 1; x >= 0 && keys[x] != ' '; x-- {
+				c.styles[y][b.x+1+x] = erKeyMark
+			}
+		}
+		c.put(b.x+b.width-1, y, '│', erBorder)
+	}
+	c.text(b.x, b.y+b.height()-1, "└"+strings.Repeat("─", inner)+"┘", erBorder)
+}
+
+var mermaidUnsafe = regexp.MustCompile(`[^A-Za-z0-9_\-()\[\]]+`)
+
+// mermaid renders the diagram as a Mermaid erDiagram.
+func (d erDiagram) mermaid() string {
+	ids := d.ids(func(s string) string { return mermaidUnsafe.ReplaceAllString(s, "_") })
+	var out strings.Builder
+	out.WriteString("erDiagram\n")
+	for i, t := range d.tables {
+		fmt.Fprintf(&out, "    %s {\n", ids[i])
+		for _, column := range t.columns {
+			line := "        " + mermaidUnsafe.ReplaceAllString(column.dataType, "_") + " " + mermaidUnsafe.ReplaceAllString(column.name, "_")
+			if keys := column.keys(); keys != "" {
+				line += " " + strings.ReplaceAll(keys, ",", ", ")
+			}
+			out.WriteString(line + "\n")
+		}
+		out.WriteString("    }\n")
+	}
+	for _, e := range d.edges {
+		fmt.Fprintf(&out, "    %s ||--o{ %s : %q\n", ids[e.to], ids[e.from], e.key.name)
+	}
+	return out.String()
+}
+
+// dot renders the diagram for Graphviz, with a port per column so the
+// edges join the key columns.
+func (d erDiagram) dot() string {
+	ids := d.ids(func(s string) string { return s })
+	port := func(t erTable, column string) string {
+		for i, c := range t.columns {
+			if c.name == column {
+				return fmt.Sprintf("c%d", i)
+			}
+		}
+		return "c0"
+	}
+	var out strings.Builder
+	out.WriteString("digraph er {\n    rankdir=LR;\n    node [shape=plaintext];\n")
+	for i, t := range d.tables {
+		fmt.Fprintf(&out, "    %q [label=<<table border=\"0\" cellborder=\"1\" cellspacing=\"0\">"+
+			"<tr><td bgcolor=\"lightgrey\"><b>%s</b></td></tr>", ids[i], html.EscapeString(d.label(t)))
+		for j, column := range t.columns {
+			text := column.name + " " + column.dataType
+			if keys := column.keys(); keys != "" {
+				text += " (" + keys + ")"
+			}
+			fmt.Fprintf(&out, "<tr><td port=\"c%d\" align=\"left\">%s</td></tr>", j, html.EscapeString(text))
+		}
+		out.WriteString("</table>>];\n")
+	}
+	for _, e := range d.edges {
+		fmt.Fprintf(&out, "    %q:%s -> %q:%s [label=%q];\n",
+			ids[e.from], port(d.tables[e.from], e.key.columns[0]),
+			ids[e.to], port(d.tables[e.to], e.key.refColumns[0]), e.key.name)
+	}
+	out.WriteString("}\n")
+	return out.String()
+}
+
+// ids names the tables for an export: the bare name unless it is taken by a
+// table in another schema.
+func (d erDiagram) ids(clean func(string) string) []string {
+	count := map[string]int{}
+	for _, t := range d.tables {
+		count[t.name]++
+	}
+	ids := make([]string, len(d.tables))
+	for i, t := range d.tables {
+		ids[i] = clean(t.name)
+		if count[t.name] > 1 {
+			ids[i] = clean(t.schema + "_" + t.name)
+		}
+	}
+	return ids
+}
+
+func (m model) updateERDiagram(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	d := &m.er
+	viewWidth, viewHeight := m.TotalWidth-8, max(m.RHeight-7, 1)
+	switch msg.String() {
+	case "esc", "q":
+		m.closeERDiagram()
+	case "left", "h":
+		d.x -= 4
+	case "right", "l":
+		d.x += 4
+	case "up", "k":
+		d.y -= 2
+	case "down", "j":
+		d.y += 2
+	case "H":
+		d.x -= viewWidth
+	case "L":
+		d.x += viewWidth
+	case "K", "pgup":
+		d.y -= viewHeight
+	case "J", "pgdown":
+		d.y += viewHeight
+	case "g", "home":
+		d.x, d.y = 0, 0
+	case "+", "=":
+		if d.table != "" {
+			d.hops++
+			if err := d.load(m.db); err != nil {
+				m.setQueryError(err)
+			}
+		}
+	case "-":
+		if d.table != "" && d.hops > 1 {
+			d.hops--
+			if err := d.load(m.db); err != nil {
+				m.setQueryError(err)
+			}
+		}
+	case "s":
+		if d.table != "" {
+			d.table = ""
+		} else {
+			d.table = d.focus
+		}
+		if err := d.load(m.db); err != nil {
+			m.setQueryError(err)
+		}
+	case "m":
+		m.copyToClipboard(d.mermaid(), "Mermaid diagram")
+	case "d":
+		m.copyToClipboard(d.dot(), "Graphviz DOT")
+	}
+	if len(d.canvas) > 0 {
+		d.x = max(min(d.x, len(d.canvas[0])-viewWidth), 0)
+	}
+	d.y = max(min(d.y, len(d.canvas)-viewHeight), 0)
+	return m, nil
+}
+
+var erStyles = map[erStyle]lipgloss.Style{
+	erText:    lipgloss.NewStyle(),
+	erBorder:  lipgloss.NewStyle().Foreground(lipgloss.Color("240")),
+	erLine:    lipgloss.NewStyle().Foreground(lipgloss.Color("6")),
+	erTitle:   lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("5")),
+	erFocus:   lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("229")).Background(lipgloss.Color("57")),
+	erKeyMark: lipgloss.NewStyle().Foreground(lipgloss.Color("3")),
+}
+
+func (m model) erView() string {
+	d := m.er
+	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("6"))
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+	width := m.TotalWidth - 8
+	height := max(m.RHeight-7, 1)
+
+	scope := "schema " + d.schema
+	keys := "s: around " + d.focus
+	if d.table != "" {
+		scope = fmt.Sprintf("%s and %d hop", qualifiedName(d.schema, d.table), d.hops)
+		if d.hops != 1 {
+			scope += "s"
+		}
+		keys = "+/-: hops · s: whole schema"
+	}
+	lines := []string{
+		title.Render(fmt.Sprintf("ER diagram · %s · %d tables, %d foreign keys", scope, len(d.tables), len(d.edges))),
+		dim.Render(strings.TrimPrefix(keys+" · ", " · ") + "←↑↓→/hjkl: pan (HJKL by a page) · m: copy Mermaid · d: copy DOT · esc: close"),
+	}
+	if len(d.tables) == 0 {
+		return strings.Join(append(lines, "No tables found."), "\n")
+	}
+
+	for y := d.y; y < min(d.y+height, len(d.canvas)); y++ {
+		row, styles := d.canvas[y], d.styles[y]
+		end := min(d.x+width, len(row))
+		var line strings.Builder
+		for x := d.x; x < end; {
+			run := x
+			for run < end && styles[run] == styles[x] {
+				run++
+			}
+			line.WriteString(erStyles[styles[x]].Render(string(row[x:run])))
+			x = run
+		}
+		lines = append(lines, line.String())
+	}
+	return strings.Join(lines, "\n")
+}
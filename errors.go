@@ -0,0 +1,171 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/lib/pq"
+)
+
+var sgrPattern = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// errorMark points at the character a Postgres error refers to, in the
+// editor buffer the failing statement was run from.
+type errorMark struct {
+	buffer      string
+	line        int // 1-based, for the error panel
+	column      int
+	displayLine int // wrapped row in the textarea, as passed to the prompt func
+	cell        int // display column inside that row
+	width       int
+}
+
+func (m *model) setQueryError(err error) {
+	m.queryError = err.Error()
+	m.pgError = nil
+	var pgErr *pq.Error
+	if errors.As(err, &pgErr) {
+		m.pgError = pgErr
+	}
+}
+
+// currentPGError returns the structured error behind the status bar message,
+// if the message still is the one it produced.
+func (m model) currentPGError() *pq.Error {
+	if m.pgError == nil || m.queryError != m.pgError.Error() {
+		return nil
+	}
+	return m.pgError
+}
+
+// markErrorPosition moves the editor cursor to the character the error
+// position points at and remembers where to underline it. start is the byte
+// offset of query in the editor buffer.
+func (m *model) markErrorPosition(query string, start int) {
+	pgErr := m.currentPGError()
+	if pgErr == nil || pgErr.Position == "" {
+		return
+	}
+	position, err := strconv.Atoi(pgErr.Position)
+	runes := []rune(query)
+	if err != nil || position < 1 || position > len(runes) {
+		return
+	}
+	index := len(string(runes[:position-1]))
+
+	// Underline the whole token the error starts at, up to the end of its line.
+	width := 1
+	for _, token := range tokenizeSQL(query) {
+		if token.start <= index && index < token.start+len(token.text) {
+			text, _, _ := strings.Cut(query[index:token.start+len(token.text)], "\n")
+			width = max(ansi.StringWidth(text), 1)
+			break
+		}
+	}
+
+	value := m.editor.Value()
+	offset := start + index
+	line := strings.Count(value[:offset], "\n")
+	lines := strings.Split(value, "\n")
+
+	// Count the wrapped rows above the error to find its display line.
+	displayLine, lineStart := 0, 0
+	for i := 0; i < line; i++ {
+		moveCursorToOffset(&m.editor, lineStart)
+		displayLine += m.editor.LineInfo().Height
+		lineStart += len(lines[i]) + 1
+	}
+	moveCursorToOffset(&m.editor, offset)
+	info := m.editor.LineInfo()
+
+	m.errorMark = errorMark{
+		buffer:      value,
+		line:        line + 1,
+		column:      len([]rune(value[lineStart:offset])) + 1,
+		displayLine: displayLine + info.RowOffset,
+		cell:        info.CharOffset,
+		width:       min(width, m.editor.Width()-info.CharOffset),
+	}
+	prompt, marked := m.editor.Prompt, m.errorMark.displayLine
+	m.editor.SetPromptFunc(ansi.StringWidth(prompt), func(displayLine int) string {
+		if displayLine == marked {
+			return "✖ "
+		}
+		return prompt
+	})
+}
+
+func (m *model) clearErrorMark() {
+	if m.errorMark.buffer == "" {
+		return
+	}
+	m.errorMark = errorMark{}
+	prompt := m.editor.Prompt
+	m.editor.SetPromptFunc(ansi.StringWidth(prompt), func(int) string { return prompt })
+}
+
+// underlineError underlines the marked characters in the rendered editor.
+// The row is found by the ✖ prompt, so the textarea's scroll offset doesn't
+// matter.
+func (m model) underlineError(view string) string {
+	mark := m.errorMark
+	if mark.buffer == "" || mark.buffer != m.editor.Value() {
+		return view
+	}
+	gutter := ansi.StringWidth(m.editor.Prompt)
+	if m.editor.ShowLineNumbers {
+		gutter += len(strconv.Itoa(m.editor.MaxHeight)) + 2
+	}
+
+	lines := strings.Split(view, "\n")
+	for i, line := range lines {
+		if !strings.HasPrefix(ansi.Strip(line), "✖") {
+			continue
+		}
+		left, right := gutter+mark.cell, gutter+mark.cell+mark.width
+		marked := ansi.Cut(line, left, right)
+		marked = "\x1b[4m" + sgrPattern.ReplaceAllString(marked, "$0\x1b[4m") + "\x1b[24m"
+		lines[i] = ansi.Cut(line, 0, left) + marked + ansi.Cut(line, right, ansi.StringWidth(line))
+		break
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (m model) errorPanelView() string {
+	pgErr := m.currentPGError()
+	if pgErr == nil {
+		return ""
+	}
+	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("196"))
+	label := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+
+	lines := []string{
+		title.Render(fmt.Sprintf("%s %s (%s)  ", pgErr.Severity, pgErr.Code, pgErr.Code.Name())) +
+			label.Render("ctrl+o: close"),
+		pgErr.Message,
+	}
+	field := func(name, value string) {
+		if value != "" {
+			lines = append(lines, label.Render(fmt.Sprintf("%-12s", name))+value)
+		}
+	}
+	field("Detail", pgErr.Detail)
+	field("Hint", pgErr.Hint)
+	if m.errorMark.buffer != "" {
+		field("Position", fmt.Sprintf("line %d, column %d", m.errorMark.line, m.errorMark.column))
+	}
+	field("Where", pgErr.Where)
+	field("Schema", pgErr.Schema)
+	field("Table", pgErr.Table)
+	field("Column", pgErr.Column)
+	field("Constraint", pgErr.Constraint)
+	field("Data type", pgErr.DataTypeName)
+	field("Query", pgErr.InternalQuery)
+	field("Routine", pgErr.Routine)
+	return strings.Join(lines, "\n")
+}
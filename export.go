@@ -0,0 +1,212 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	exportBatchSizes = []int{1, 10, 100, 500, 1000}
+	numberPattern    = regexp.MustCompile(`^-?\d+(\.\d+)?([eE][-+]?\d+)?$`)
+)
+
+// insertExport holds the options of the "copy as INSERT" dialog.
+type insertExport struct {
+	table        string
+	batchSize    int
+	onConflict   bool
+	selectedOnly bool
+	input        textinput.Model
+	editing      string // "table" or "file" while the input is focused
+}
+
+func (m *model) openInsertExport() {
+	input := textinput.New()
+	input.CharLimit = 0
+
+	table := m.result.source
+	if table == "" {
+		table = "table_name"
+	}
+	m.export = insertExport{
+		table:        table,
+		batchSize:    100,
+		selectedOnly: len(m.marked) > 0,
+		input:        input,
+	}
+	m.showExport = true
+}
+
+func (e insertExport) tableName() string {
+	if strings.ContainsAny(e.table, "\"`") {
+		return e.table
+	}
+	parts := strings.Split(e.table, ".")
+	for i, part := range parts {
+		parts[i] = activeDriver.quoteIdentifier(part)
+	}
+	return strings.Join(parts, ".")
+}
+
+// insertStatements renders the chosen rows as multi-row INSERTs, using the
+// visible columns in grid order.
+func (m model) insertStatements() string {
+	columns := m.grid.visibleColumns()
+	names := make([]string, len(columns))
+	for i, col := range columns {
+		names[i] = m.result.columns[col]
+	}
+
+	var rows []int
+	for row := range m.result.rows {
+		if !m.export.selectedOnly || m.marked[row] {
+			rows = append(rows, row)
+		}
+	}
+
+	var out strings.Builder
+	for start := 0; start < len(rows); start += m.export.batchSize {
+		batch := rows[start:min(start+m.export.batchSize, len(rows))]
+		fmt.Fprintf(&out, "INSERT INTO %s (%s) VALUES\n", m.export.tableName(), quoteIdentifiers(names))
+		for i, row := range batch {
+			values := make([]string, len(columns))
+			for j, col := range columns {
+				values[j] = typedLiteral(m.result.rows[row][col], m.result.nulls[row][col], m.result.types[col])
+			}
+			separator := ","
+			if i == len(batch)-1 {
+				separator = ""
+			}
+			fmt.Fprintf(&out, "  (%s)%s\n", strings.Join(values, ", "), separator)
+		}
+		if m.export.onConflict {
+			out.WriteString("ON CONFLICT DO NOTHING")
+		}
+		out.WriteString(";\n")
+	}
+	return out.String()
+}
+
+// typedLiteral writes value the way Postgres reads it back for the column
+// type: bare numbers and booleans, hex bytea, quoted text for the rest.
+func typedLiteral(value string, null bool, dataType string) string {
+	if null {
+		return "NULL"
+	}
+	switch dataType {
+	case "INT2", "INT4", "INT8", "OID", "FLOAT4", "FLOAT8", "NUMERIC":
+		// NaN and Infinity have to stay quoted.
+		if numberPattern.MatchString(value) {
+			return value
+		}
+	case "BOOL":
+		switch value {
+		case "true", "t":
+			return "TRUE"
+		case "false", "f":
+			return "FALSE"
+		}
+	case "BYTEA":
+		return `'\x` + hex.EncodeToString([]byte(value)) + `'`
+	}
+	return activeDriver.quoteLiteral(value)
+}
+
+func (m model) updateInsertExport(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	e := &m.export
+	if e.input.Focused() {
+		switch msg.String() {
+		case "enter":
+			value := strings.TrimSpace(e.input.Value())
+			e.input.Blur()
+			if e.editing == "table" && value != "" {
+				e.table = value
+			} else if e.editing == "file" && value != "" {
+				if err := os.WriteFile(value, []byte(m.insertStatements()), 0644); err != nil {
+					m.setQueryError(err)
+				} else {
+					m.showExport = false
+					m.statusMessage = "Wrote INSERT statements to " + value
+				}
+			}
+		case "esc":
+			e.input.Blur()
+		default:
+			var cmd tea.Cmd
+			e.input, cmd = e.input.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "esc", "q":
+		m.showExport = false
+	case "t":
+		e.editing = "table"
+		e.input.Prompt = "Table: "
+		e.input.SetValue(e.table)
+		e.input.CursorEnd()
+		return m, e.input.Focus()
+	case "b":
+		next := 0
+		for i, size := range exportBatchSizes {
+			if size == e.batchSize {
+				next = (i + 1) % len(exportBatchSizes)
+			}
+		}
+		e.batchSize = exportBatchSizes[next]
+	case "o":
+		e.onConflict = !e.onConflict
+	case "s":
+		e.selectedOnly = !e.selectedOnly && len(m.marked) > 0
+	case "c", "enter":
+		m.copyToClipboard(m.insertStatements(), "INSERT statements")
+		if m.queryError == "" {
+			m.showExport = false
+		}
+	case "w":
+		e.editing = "file"
+		e.input.Prompt = "File: "
+		e.input.SetValue("export.sql")
+		e.input.CursorEnd()
+		return m, e.input.Focus()
+	}
+	return m, nil
+}
+
+func (m model) insertExportView() string {
+	e := m.export
+	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("6"))
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+
+	rows := len(m.result.rows)
+	if e.selectedOnly {
+		rows = len(m.marked)
+	}
+	onConflict := "no"
+	if e.onConflict {
+		onConflict = "ON CONFLICT DO NOTHING"
+	}
+
+	lines := []string{
+		title.Render(fmt.Sprintf("Export %d rows as INSERT statements", rows)),
+		fmt.Sprintf("Table        %s  %s", e.tableName(), dim.Render("(t)")),
+		fmt.Sprintf("Batch size   %d rows per statement  %s", e.batchSize, dim.Render("(b)")),
+		fmt.Sprintf("On conflict  %s  %s", onConflict, dim.Render("(o)")),
+		fmt.Sprintf("Rows         %s  %s", map[bool]string{true: "marked rows", false: "all rows"}[e.selectedOnly], dim.Render("(s, mark rows with m)")),
+	}
+	if e.input.Focused() {
+		lines = append(lines, e.input.View())
+	} else {
+		lines = append(lines, dim.Render("c/enter: copy to clipboard · w: write to file · esc: cancel"))
+	}
+	return strings.Join(lines, "\n")
+}
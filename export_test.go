@@ -0,0 +1,26 @@
+package main
+
+import "testing"
+
+func TestTypedLiteral(t *testing.T) {
+	tests := []struct {
+		value    string
+		null     bool
+		dataType string
+		want     string
+	}{
+		{"42", false, "INT4", "42"},
+		{"1e5", false, "NUMERIC", "1e5"},
+		{"NaN", false, "FLOAT8", "'NaN'"},
+		{"t", false, "BOOL", "TRUE"},
+		{"false", false, "BOOL", "FALSE"},
+		{"ab", false, "BYTEA", `'\x6162'`},
+		{"it's", false, "TEXT", "'it''s'"},
+		{"x", true, "TEXT", "NULL"},
+	}
+	for _, tt := range tests {
+		if got := typedLiteral(tt.value, tt.null, tt.dataType); got != tt.want {
+			t.Errorf("typedLiteral(%q, %v, %s) = %s, want %s", tt.value, tt.null, tt.dataType, got, tt.want)
+		}
+	}
+}
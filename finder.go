@@ -0,0 +1,288 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/sahilm/fuzzy"
+)
+
+// catalogObject is one thing the object finder can go to.
+type catalogObject struct {
+	kind    string // "table", "view", "column", "function" or "procedure"
+	schema  string
+	table   string // Owning table of a column
+	name    string
+	args    string // Identity arguments of a function
+	comment string
+}
+
+// label is what the finder shows and matches: schema.table, table.column or
+// schema.function(args).
+func (o catalogObject) label() string {
+	switch o.kind {
+	case "column":
+		return o.table + "." + o.name
+	case "function", "procedure":
+		return o.schema + "." + o.name + "(" + o.args + ")"
+	}
+	return o.schema + "." + o.name
+}
+
+// sqlName is the quoted name to use in a statement.
+func (o catalogObject) sqlName() string {
+	if o.kind == "column" {
+		return qualifiedName(o.schema, o.table) + "." + activeDriver.quoteIdentifier(o.name)
+	}
+	return qualifiedName(o.schema, o.name)
+}
+
+// catalogObjects is the fuzzy.Source of the finder; comments are matched
+// after the name, so a hit in the name scores higher.
+type catalogObjects []catalogObject
+
+func (c catalogObjects) String(i int) string { return c[i].label() + " " + c[i].comment }
+func (c catalogObjects) Len() int            { return len(c) }
+
+// queryCatalogObjects scans rows of kind, schema, table, name, arguments
+// and comment.
+func queryCatalogObjects(db *sql.DB, query string, args ...interface{}) ([]catalogObject, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var objects []catalogObject
+	for rows.Next() {
+		var o catalogObject
+		if err := rows.Scan(&o.kind, &o.schema, &o.table, &o.name, &o.args, &o.comment); err != nil {
+			return nil, err
+		}
+		objects = append(objects, o)
+	}
+	return objects, rows.Err()
+}
+
+type catalogObjectsMsg struct {
+	generation int
+	objects    []catalogObject
+	err        error
+}
+
+func loadCatalogObjects(db *sql.DB, generation int) tea.Cmd {
+	return func() tea.Msg {
+		objects, err := activeDriver.objects(db)
+		return catalogObjectsMsg{generation: generation, objects: objects, err: err}
+	}
+}
+
+// objectFinder searches every table, view, column and function of the
+// connection, not just the level the database list shows. The objects come
+// from the metadata cache and load the first time the finder opens.
+type objectFinder struct {
+	input   textinput.Model
+	matches []int // indexes into the cached objects, best match first
+	cursor  int
+}
+
+func newObjectFinder() objectFinder {
+	input := textinput.New()
+	input.Prompt = "❯ "
+	input.Placeholder = "table, view, function, table.column or comment"
+	input.CharLimit = 0
+	return objectFinder{input: input}
+}
+
+func (m *model) openFinder() tea.Cmd {
+	m.showFinder = true
+	m.finder.input.SetValue("")
+	m.finder.cursor = 0
+	m.editor.Blur()
+	m.filterObjects()
+	cmd := m.finder.input.Focus()
+	if m.metadata.objects == nil {
+		return tea.Batch(cmd, loadCatalogObjects(m.db, m.metadata.generation))
+	}
+	return cmd
+}
+
+func (m *model) closeFinder() {
+	m.showFinder = false
+	m.finder.input.Blur()
+	m.focusState = focusEditor
+	m.dbList.SetFilteringEnabled(false)
+	m.editor.Focus()
+}
+
+func (m *model) filterObjects() {
+	f := &m.finder
+	objects := catalogObjects(m.metadata.objects)
+	f.matches = f.matches[:0]
+	if pattern := strings.TrimSpace(f.input.Value()); pattern != "" {
+		for _, match := range fuzzy.FindFrom(pattern, objects) {
+			f.matches = append(f.matches, match.Index)
+		}
+	} else {
+		for i := range objects {
+			f.matches = append(f.matches, i)
+		}
+	}
+	f.cursor = min(f.cursor, max(len(f.matches)-1, 0))
+}
+
+func (m model) updateCatalogObjects(msg catalogObjectsMsg) (tea.Model, tea.Cmd) {
+	if msg.generation != m.metadata.generation {
+		return m, nil
+	}
+	if msg.err != nil {
+		m.setQueryError(msg.err)
+		return m, nil
+	}
+	m.metadata.objects = msg.objects
+	if m.metadata.objects == nil {
+		m.metadata.objects = []catalogObject{}
+	}
+	m.filterObjects()
+	return m, nil
+}
+
+// goToObject shows a table or view in the database list, with the column
+// selected for a column, or adds a function's definition to the editor.
+func (m *model) goToObject(o catalogObject) tea.Cmd {
+	m.closeFinder()
+	if o.kind == "function" || o.kind == "procedure" {
+		m.showRoutineDefinition(o)
+		return nil
+	}
+
+	table, column := o.name, ""
+	if o.kind == "column" {
+		table, column = o.table, o.name
+	}
+
+	m.metadata.pending = nil
+	m.listParents = nil
+	m.setListItems(m.metadata.items[metadataKey{}])
+	if _, ok := activeDriver.(databaseDriver); ok {
+		database := o.schema
+		if switcher, ok := activeDriver.(databaseSwitcher); ok {
+			var err error
+			if database, err = switcher.currentDatabase(m.db); err != nil {
+				m.setQueryError(err)
+				return nil
+			}
+		}
+		parent := dbItem{name: database, kind: "db", schema: database}
+		parent.child = m.metadata.items[metadataKey{kind: "tables", name: database}]
+		m.listParents = []dbItem{parent}
+		m.setListItems(parent.child)
+	}
+	m.insideColumns = len(m.listParents) > 0
+
+	m.dbList.ResetFilter()
+	m.focusState = focusList
+	m.dbList.SetFilteringEnabled(true)
+	m.editor.Blur()
+	m.currentTable, m.currentSchema = table, o.schema
+	item := dbItem{name: table, kind: "tables", schema: o.schema}
+	key, _ := metadataKeyFor(item)
+	return m.enterMetadata(item, key, column)
+}
+
+// showRoutineDefinition adds the CREATE statement of a function or
+// procedure to the editor.
+func (m *model) showRoutineDefinition(o catalogObject) {
+	driver, ok := activeDriver.(routineDriver)
+	if !ok {
+		m.queryError = fmt.Sprintf("Showing a function definition is not supported on %s", activeDriver.name())
+		return
+	}
+	statement, err := driver.routineDefinition(m.db, o)
+	if err != nil {
+		m.setQueryError(err)
+		return
+	}
+	m.appendDefinition(statement)
+}
+
+func (m model) updateFinder(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	f := &m.finder
+	switch msg.String() {
+	case "esc":
+		m.closeFinder()
+	case "up", "ctrl+p", "ctrl+k":
+		f.cursor = max(f.cursor-1, 0)
+	case "down", "ctrl+n", "ctrl+j":
+		f.cursor = min(f.cursor+1, max(len(f.matches)-1, 0))
+	case "pgup":
+		f.cursor = max(f.cursor-10, 0)
+	case "pgdown":
+		f.cursor = min(f.cursor+10, max(len(f.matches)-1, 0))
+	case "enter":
+		if f.cursor < len(f.matches) {
+			return m, m.goToObject(m.metadata.objects[f.matches[f.cursor]])
+		}
+	case "tab":
+		if f.cursor < len(f.matches) {
+			name := m.metadata.objects[f.matches[f.cursor]].sqlName()
+			m.closeFinder()
+			m.editor.InsertString(name)
+		}
+	default:
+		value := f.input.Value()
+		var cmd tea.Cmd
+		f.input, cmd = f.input.Update(msg)
+		if f.input.Value() != value {
+			f.cursor = 0
+			m.filterObjects()
+		}
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m model) finderView() string {
+	f := m.finder
+	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("6"))
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+	selected := lipgloss.NewStyle().Foreground(lipgloss.Color("229")).Background(lipgloss.Color("57"))
+	width := m.TotalWidth - 8
+	height := max(m.RHeight-6, 4)
+
+	heading := "Go to object · loading the catalog…"
+	if m.metadata.objects != nil {
+		heading = fmt.Sprintf("Go to object · %d of %d", len(f.matches), len(m.metadata.objects))
+	}
+	lines := []string{
+		title.Render(heading),
+		f.input.View(),
+		dim.Render("enter: open · tab: insert the name into the editor · ↑/↓: move · esc: close"),
+	}
+
+	// Only the window around the cursor is drawn; catalogs can be huge.
+	visible := max(height-len(lines), 1)
+	start := max(f.cursor-visible+1, 0)
+	var rows []string
+	for i := start; i < min(start+visible, len(f.matches)); i++ {
+		o := m.metadata.objects[f.matches[i]]
+		line := fmt.Sprintf("%-9s %s", o.kind, o.label())
+		if o.kind == "column" {
+			line += "  " + dim.Render(o.schema)
+		}
+		if o.comment != "" {
+			line += "  " + dim.Render("-- "+strings.Join(strings.Fields(o.comment), " "))
+		}
+		line = ansi.Truncate(line, width, "…")
+		if i == f.cursor {
+			line = selected.Render(ansi.Strip(line))
+		}
+		rows = append(rows, line)
+	}
+	return strings.Join(append(lines, rows...), "\n")
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type foreignKey struct {
+	name       string
+	table      string
+	columns    []string
+	refTable   string
+	refColumns []string
+}
+
+// resultCrumb remembers a result left by following a foreign key, so
+// backspace in the grid can return to it like it does in the database list.
+type resultCrumb struct {
+	label     string
+	result    resultSet
+	grid      gridLayout
+	colCursor int
+	rowCursor int
+	browsing  bool
+	browser   tableBrowser
+}
+
+// fkReference is one way to reach other rows from the selected row: a child
+// table whose foreign key points at it, with the matching row count.
+type fkReference struct {
+	key    foreignKey
+	values []string
+	args   []interface{}
+	count  int
+}
+
+func (m *model) pushCrumb(label string) {
+	m.trail = append(m.trail, resultCrumb{
+		label:     label,
+		result:    m.result,
+		grid:      m.grid,
+		colCursor: m.colCursor,
+		rowCursor: m.resultsTable.Cursor(),
+		browsing:  m.browsing,
+		browser:   m.browser,
+	})
+}
+
+func (m *model) popCrumb() {
+	if len(m.trail) == 0 {
+		return
+	}
+	crumb := m.trail[len(m.trail)-1]
+	m.trail = m.trail[:len(m.trail)-1]
+
+	m.browsing, m.browser = crumb.browsing, crumb.browser
+	m.setResults(crumb.result)
+	m.grid, m.colCursor = crumb.grid, crumb.colCursor
+	m.layoutResults()
+	m.resultsTable.SetCursor(crumb.rowCursor)
+	m.resultsTable.Focus()
+}
+
+func (m model) trailView() string {
+	labels := make([]string, 0, len(m.trail)+1)
+	for _, crumb := range m.trail {
+		labels = append(labels, crumb.label)
+	}
+	labels = append(labels, lipgloss.NewStyle().Bold(true).Render(m.result.source))
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("6")).
+		Render("⟵ " + strings.Join(labels, " › ") + "  (backspace: back)")
+}
+
+// rowValues returns the given columns of row as shown and as scanned, or
+// false when one of them is missing or NULL.
+func (m model) rowValues(row int, columns []string) ([]string, []interface{}, bool) {
+	args, ok := m.result.keyValues(row, columns)
+	if !ok {
+		return nil, nil, false
+	}
+	values := make([]string, len(columns))
+	for i, name := range columns {
+		values[i] = m.result.rows[row][indexOfString(m.result.columns, name)]
+	}
+	return values, args, true
+}
+
+// keyQuery selects the given select list from the rows of table whose
+// columns equal the arguments.
+func keyQuery(selectList, table string, columns []string) string {
+	conditions := make([]string, len(columns))
+	for i, column := range columns {
+		conditions[i] = activeDriver.quoteIdentifier(column) + " = " + activeDriver.placeholder(i+1)
+	}
+	return fmt.Sprintf("SELECT %s FROM %s WHERE %s", selectList, table, strings.Join(conditions, " AND "))
+}
+
+func inlineKeyQuery(table string, columns, values []string) string {
+	conditions := make([]string, len(columns))
+	for i, column := range columns {
+		conditions[i] = activeDriver.quoteIdentifier(column) + " = " + activeDriver.quoteLiteral(values[i])
+	}
+	return fmt.Sprintf("SELECT * FROM %s WHERE %s", table, strings.Join(conditions, " AND "))
+}
+
+// followForeignKey jumps from the selected cell to the row it references.
+func (m *model) followForeignKey() {
+	if !m.requirePostgres("Following foreign keys") || m.editsBlockPaging() {
+		return
+	}
+	row, col := m.resultsTable.Cursor(), m.selectedColumn()
+	if row >= len(m.result.rows) || col < 0 {
+		return
+	}
+	if m.result.source == "" {
+		m.queryError = "foreign keys unknown: the result does not come from a single table"
+		return
+	}
+
+	keys, err := getForeignKeys(m.db, m.result.source)
+	if err != nil {
+		m.setQueryError(err)
+		return
+	}
+
+	column := m.result.columns[col]
+	for _, key := range keys {
+		if !containsString(key.columns, column) {
+			continue
+		}
+		values, args, ok := m.rowValues(row, key.columns)
+		if !ok {
+			m.queryError = fmt.Sprintf("%s: key is NULL or not fully selected", key.name)
+			return
+		}
+		m.openKeyedRows(key.refTable, key.refColumns, values, args,
+			fmt.Sprintf("%s.%s=%s", m.result.source, column, m.result.rows[row][col]))
+		return
+	}
+	m.queryError = fmt.Sprintf("%s is not part of a foreign key", column)
+}
+
+// showReferencingRows lists the child tables pointing at the selected row;
+// with a single candidate it opens the rows straight away.
+func (m *model) showReferencingRows() {
+	if !m.requirePostgres("Following foreign keys") || m.editsBlockPaging() {
+		return
+	}
+	row := m.resultsTable.Cursor()
+	if row >= len(m.result.rows) {
+		return
+	}
+	if m.result.source == "" {
+		m.queryError = "foreign keys unknown: the result does not come from a single table"
+		return
+	}
+
+	keys, err := getReferencingKeys(m.db, m.result.source)
+	if err != nil {
+		m.setQueryError(err)
+		return
+	}
+
+	var references []fkReference
+	for _, key := range keys {
+		values, args, ok := m.rowValues(row, key.refColumns)
+		if !ok {
+			continue
+		}
+		var count int
+		if err := m.db.QueryRow(keyQuery("count(*)", key.table, key.columns), args...).Scan(&count); err != nil {
+			m.setQueryError(err)
+			return
+		}
+		references = append(references, fkReference{key: key, values: values, args: args, count: count})
+	}
+
+	switch len(references) {
+	case 0:
+		m.queryError = fmt.Sprintf("no table references %s through selected columns", m.result.source)
+	case 1:
+		m.openReference(references[0])
+	default:
+		m.fkReferences = references
+		m.fkCursor = 0
+		m.showFKPicker = true
+	}
+}
+
+func (m *model) openReference(ref fkReference) {
+	m.openKeyedRows(ref.key.table, ref.key.columns, ref.values, ref.args,
+		fmt.Sprintf("%s←%s", m.result.source, ref.key.table))
+}
+
+func (m *model) openKeyedRows(table string, columns, values []string, args []interface{}, label string) {
+	if m.editsBlockPaging() {
+		return
+	}
+	rs, err := runQuery(m.db, keyQuery("*", table, columns), args...)
+	if err != nil {
+		m.setQueryError(err)
+		return
+	}
+	rs.source = table
+	rs.query = inlineKeyQuery(table, columns, values)
+
+	m.pushCrumb(label)
+	m.browsing = false
+	m.setResults(rs)
+	m.resultsTable.Focus()
+}
+
+func (m model) updateFKPicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		m.showFKPicker = false
+	case "up", "k":
+		m.fkCursor = max(m.fkCursor-1, 0)
+	case "down", "j":
+		m.fkCursor = min(m.fkCursor+1, len(m.fkReferences)-1)
+	case "enter":
+		m.showFKPicker = false
+		m.openReference(m.fkReferences[m.fkCursor])
+	}
+	return m, nil
+}
+
+func (m model) fkPickerView() string {
+	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("6")).
+		Render("Rows referencing " + m.result.source + "  (enter: open · esc: close)")
+
+	lines := []string{title}
+	for i, ref := range m.fkReferences {
+		line := fmt.Sprintf("%s (%s) → %d rows", ref.key.table, strings.Join(ref.key.columns, ", "), ref.count)
+		if i == m.fkCursor {
+			line = lipgloss.NewStyle().Foreground(lipgloss.Color("229")).Background(lipgloss.Color("57")).Render(line)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
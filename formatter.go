@@ -0,0 +1,626 @@
+package main
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type sqlTokenKind int
+
+const (
+	tokenSpace sqlTokenKind = iota
+	tokenWord
+	tokenQuotedIdent
+	tokenString
+	tokenNumber
+	tokenLineComment
+	tokenBlockComment
+	tokenPunct
+	tokenOperator
+)
+
+type sqlToken struct {
+	kind     sqlTokenKind
+	text     string
+	start    int
+	sameLine bool // no line break between this token and the previous one
+}
+
+const operatorChars = "+-*/<>=~!@#%^&|?:"
+
+// tokenizeSQL splits s into tokens, keeping strings, dollar-quoted bodies,
+// quoted identifiers and comments intact so nothing inside them is
+// reformatted or mistaken for a statement boundary.
+func tokenizeSQL(s string) []sqlToken {
+	var tokens []sqlToken
+	sameLine := true
+	i := 0
+	for i < len(s) {
+		start := i
+		c := s[i]
+		kind := tokenOperator
+
+		switch {
+		case unicode.IsSpace(rune(c)):
+			for i < len(s) && unicode.IsSpace(rune(s[i])) {
+				i++
+			}
+			kind = tokenSpace
+		case strings.HasPrefix(s[i:], "--"):
+			for i < len(s) && s[i] != '\n' {
+				i++
+			}
+			kind = tokenLineComment
+		case strings.HasPrefix(s[i:], "/*"):
+			depth := 0
+			for i < len(s) {
+				if strings.HasPrefix(s[i:], "/*") {
+					depth++
+					i += 2
+				} else if strings.HasPrefix(s[i:], "*/") {
+					depth--
+					i += 2
+					if depth == 0 {
+						break
+					}
+				} else {
+					i++
+				}
+			}
+			kind = tokenBlockComment
+		case c == '\'':
+			i = scanQuoted(s, i, '\'', false)
+			kind = tokenString
+		case (c == 'E' || c == 'e') && i+1 < len(s) && s[i+1] == '\'':
+			i = scanQuoted(s, i+1, '\'', true)
+			kind = tokenString
+		case c == '"' || c == '`': // backticks quote identifiers in MySQL
+			i = scanQuoted(s, i, c, false)
+			kind = tokenQuotedIdent
+		case c == '$' && i+1 < len(s) && s[i+1] >= '0' && s[i+1] <= '9':
+			i++
+			for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+				i++
+			}
+			kind = tokenWord
+		case c == '$':
+			if end := scanDollarQuoted(s, i); end > i {
+				i = end
+				kind = tokenString
+			} else {
+				i++
+			}
+		case c >= '0' && c <= '9' || c == '.' && i+1 < len(s) && s[i+1] >= '0' && s[i+1] <= '9':
+			for i < len(s) && (s[i] >= '0' && s[i] <= '9' || s[i] == '.' ||
+				s[i] == 'e' || s[i] == 'E' ||
+				(s[i] == '-' || s[i] == '+') && (s[i-1] == 'e' || s[i-1] == 'E')) {
+				i++
+			}
+			kind = tokenNumber
+		case c == '_' || c >= 0x80 || unicode.IsLetter(rune(c)):
+			for i < len(s) && (s[i] == '_' || s[i] == '$' || s[i] >= 0x80 ||
+				unicode.IsLetter(rune(s[i])) || unicode.IsDigit(rune(s[i]))) {
+				i++
+			}
+			kind = tokenWord
+		case strings.ContainsRune("(),;[].", rune(c)):
+			i++
+			kind = tokenPunct
+		case strings.HasPrefix(s[i:], "::"):
+			i += 2
+		default:
+			for i < len(s) && strings.IndexByte(operatorChars, s[i]) >= 0 &&
+				!strings.HasPrefix(s[i:], "--") && !strings.HasPrefix(s[i:], "/*") {
+				i++
+			}
+			if i == start {
+				i++
+			}
+		}
+
+		token := sqlToken{kind: kind, text: s[start:i], start: start, sameLine: sameLine}
+		tokens = append(tokens, token)
+		if kind == tokenSpace {
+			sameLine = !strings.Contains(token.text, "\n")
+		} else {
+			sameLine = true
+		}
+	}
+	return tokens
+}
+
+func scanQuoted(s string, i int, quote byte, backslash bool) int {
+	i++
+	for i < len(s) {
+		switch {
+		case backslash && s[i] == '\\':
+			i += 2
+		case s[i] == quote && i+1 < len(s) && s[i+1] == quote:
+			i += 2
+		case s[i] == quote:
+			return i + 1
+		default:
+			i++
+		}
+	}
+	return len(s)
+}
+
+// scanDollarQuoted returns the end of a $tag$...$tag$ string starting at i,
+// or i when s[i:] doesn't open one.
+func scanDollarQuoted(s string, i int) int {
+	end := strings.IndexByte(s[i+1:], '$')
+	if end < 0 {
+		return i
+	}
+	tag := s[i : i+end+2]
+	for _, r := range tag[1 : len(tag)-1] {
+		if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return i
+		}
+	}
+	body := strings.Index(s[i+len(tag):], tag)
+	if body < 0 {
+		return len(s)
+	}
+	return i + len(tag) + body + len(tag)
+}
+
+type statementSpan struct{ start, end int }
+
+// statementSpans splits s into statements, which end at a semicolon or at a
+// blank line.
+func statementSpans(s string) []statementSpan {
+	var spans []statementSpan
+	current := statementSpan{start: -1}
+	flush := func() {
+		if current.start >= 0 {
+			spans = append(spans, current)
+		}
+		current = statementSpan{start: -1}
+	}
+
+	for _, token := range tokenizeSQL(s) {
+		if token.kind == tokenSpace {
+			if strings.Count(token.text, "\n") > 1 {
+				flush()
+			}
+			continue
+		}
+		if current.start < 0 {
+			current.start = token.start
+		}
+		current.end = token.start + len(token.text)
+		if token.text == ";" {
+			flush()
+		}
+	}
+	flush()
+	return spans
+}
+
+// statementBounds returns the byte range of the statement around offset.
+func statementBounds(s string, offset int) (int, int) {
+	spans := statementSpans(s)
+	if len(spans) == 0 {
+		return 0, 0
+	}
+	chosen := spans[0]
+	for _, sp := range spans {
+		if sp.start > offset {
+			break
+		}
+		chosen = sp
+	}
+	return chosen.start, chosen.end
+}
+
+type formatOptions struct {
+	keywordCase string // "upper", "lower" or "preserve"
+	indent      string
+}
+
+// formatOptionsFromEnv reads the style from SQL_FORMAT_KEYWORD_CASE and
+// SQL_FORMAT_INDENT (a number of spaces or "tab"), which can live in .env
+// next to the connection settings.
+func formatOptionsFromEnv() formatOptions {
+	opts := formatOptions{keywordCase: "upper", indent: "  "}
+	switch strings.ToLower(os.Getenv("SQL_FORMAT_KEYWORD_CASE")) {
+	case "lower":
+		opts.keywordCase = "lower"
+	case "preserve":
+		opts.keywordCase = "preserve"
+	}
+	if indent := os.Getenv("SQL_FORMAT_INDENT"); strings.EqualFold(indent, "tab") {
+		opts.indent = "\t"
+	} else if n, err := strconv.Atoi(indent); err == nil && n >= 0 && n <= 8 {
+		opts.indent = strings.Repeat(" ", n)
+	}
+	return opts
+}
+
+var sqlKeywords = map[string]bool{}
+
+func init() {
+	for _, keyword := range strings.Fields(`
+		ALL ALTER AND ANY ARRAY AS ASC BEGIN BETWEEN BY CASE CAST CHECK COMMIT
+		CONFLICT CONSTRAINT CREATE CROSS CURRENT_DATE CURRENT_TIMESTAMP DEFAULT
+		DELETE DESC DISTINCT DO DROP ELSE END EXCEPT EXISTS EXTRACT FALSE FETCH
+		FILTER FIRST FOR FOREIGN FROM FULL GROUP HAVING ILIKE IN INDEX INNER INSERT
+		INTERSECT INTO IS JOIN KEY LAST LATERAL LEFT LIKE LIMIT NATURAL NEXT NOT
+		NOTHING NOWAIT NULL NULLS OF OFFSET ON ONLY OR ORDER OUTER OVER PARTITION PRIMARY
+		RECURSIVE REFERENCES RETURNING RIGHT ROLLBACK ROW ROWS SELECT SET SOME
+		TABLE THEN TO TRUE TRUNCATE UNION UNIQUE UPDATE USING VALUES VIEW WHEN
+		WHERE WINDOW WITH`) {
+		sqlKeywords[keyword] = true
+	}
+}
+
+var (
+	listClauses   = map[string]bool{"SELECT": true, "FROM": true, "GROUP BY": true, "ORDER BY": true, "SET": true, "RETURNING": true, "VALUES": true}
+	plainClauses  = map[string]bool{"WHERE": true, "HAVING": true, "LIMIT": true, "OFFSET": true, "FETCH": true, "WINDOW": true, "WITH": true, "INSERT INTO": true, "UPDATE": true, "DELETE FROM": true, "ON CONFLICT": true}
+	setOperations = map[string]bool{"UNION": true, "INTERSECT": true, "EXCEPT": true}
+	joinWords     = map[string]bool{"NATURAL": true, "LEFT": true, "RIGHT": true, "FULL": true, "OUTER": true, "INNER": true, "CROSS": true}
+	callKeywords  = map[string]bool{"CAST": true, "EXTRACT": true, "ANY": true, "SOME": true, "ARRAY": true, "ROW": true}
+)
+
+type formatLevel struct {
+	indent     int
+	openIndent int // indent of the line holding the "(" of a subquery
+	clause     string
+	listBreak  bool
+	parens     int
+}
+
+type sqlFormatter struct {
+	opts           formatOptions
+	tokens         []sqlToken
+	lines          []string
+	line           strings.Builder
+	lineIndent     int
+	levels         []formatLevel
+	prev           *sqlToken
+	pendingNewline bool
+}
+
+// formatSQL re-indents s: one clause per line, list items and AND/OR
+// conditions on their own lines, JOIN ... ON aligned, and subqueries and CTE
+// bodies indented. Comments are kept.
+func formatSQL(s string, opts formatOptions) string {
+	f := &sqlFormatter{opts: opts, levels: []formatLevel{{}}}
+	for _, token := range tokenizeSQL(s) {
+		if token.kind != tokenSpace {
+			f.tokens = append(f.tokens, token)
+		}
+	}
+
+	for i := 0; i < len(f.tokens); i++ {
+		i = f.formatToken(i)
+	}
+	f.newline(0)
+	return strings.Join(f.lines, "\n")
+}
+
+// formatStatements formats each statement of s on its own and keeps a blank
+// line between them, so they still run one at a time with Ctrl+y.
+func formatStatements(s string, opts formatOptions) string {
+	var formatted []string
+	for _, sp := range statementSpans(s) {
+		formatted = append(formatted, formatSQL(s[sp.start:sp.end], opts))
+	}
+	return strings.Join(formatted, "\n\n")
+}
+
+func (f *sqlFormatter) level() *formatLevel {
+	return &f.levels[len(f.levels)-1]
+}
+
+func (f *sqlFormatter) upper(i int) string {
+	if i >= len(f.tokens) || f.tokens[i].kind != tokenWord {
+		return ""
+	}
+	return strings.ToUpper(f.tokens[i].text)
+}
+
+func (f *sqlFormatter) newline(indent int) {
+	if text := strings.TrimRight(f.line.String(), " \t"); strings.TrimSpace(text) != "" {
+		f.lines = append(f.lines, text)
+	}
+	f.line.Reset()
+	f.lineIndent = indent
+	f.prev = nil
+	f.pendingNewline = false
+}
+
+func (f *sqlFormatter) write(token sqlToken, text string) {
+	if f.pendingNewline {
+		f.newline(f.lineIndent)
+	}
+	if f.line.Len() == 0 {
+		f.line.WriteString(strings.Repeat(f.opts.indent, f.lineIndent))
+	} else if f.needsSpace(token) {
+		f.line.WriteByte(' ')
+	}
+	f.line.WriteString(text)
+	f.prev = &token
+}
+
+func (f *sqlFormatter) needsSpace(token sqlToken) bool {
+	prev := f.prev
+	if prev == nil {
+		return false
+	}
+	switch {
+	case token.text == "," || token.text == ";" || token.text == ")" || token.text == "]" || token.text == ".":
+		return false
+	case prev.text == "(" || prev.text == "[" || prev.text == ".":
+		return false
+	case token.text == "::" || prev.text == "::":
+		return false
+	case token.text == "(" && f.level().clause == "INSERT INTO" && f.level().parens == 1:
+		return true
+	case token.text == "(" || token.text == "[":
+		if prev.kind == tokenQuotedIdent || prev.text == ")" || prev.text == "]" {
+			return token.text == "(" && prev.text == ")"
+		}
+		if prev.kind == tokenWord {
+			upper := strings.ToUpper(prev.text)
+			return sqlKeywords[upper] && !callKeywords[upper]
+		}
+	}
+	return true
+}
+
+func (f *sqlFormatter) keyword(text string) string {
+	switch f.opts.keywordCase {
+	case "upper":
+		return strings.ToUpper(text)
+	case "lower":
+		return strings.ToLower(text)
+	}
+	return text
+}
+
+func (f *sqlFormatter) writeWords(start, count int) {
+	for j := start; j < start+count; j++ {
+		f.write(f.tokens[j], f.keyword(f.tokens[j].text))
+	}
+}
+
+// clauseAt recognises a clause keyword, possibly spanning several words,
+// and returns it with the number of tokens it covers.
+func (f *sqlFormatter) clauseAt(i int) (string, int) {
+	word := f.upper(i)
+	next := f.upper(i + 1)
+	switch {
+	case (word == "GROUP" || word == "ORDER") && next == "BY":
+		return word + " BY", 2
+	case word == "INSERT" && next == "INTO", word == "DELETE" && next == "FROM", word == "ON" && next == "CONFLICT":
+		return word + " " + next, 2
+	case setOperations[word]:
+		if next == "ALL" || next == "DISTINCT" {
+			return word, 2
+		}
+		return word, 1
+	case word == "FOR":
+		// Locking clauses: FOR UPDATE, FOR NO KEY UPDATE, FOR SHARE and FOR KEY SHARE.
+		switch {
+		case next == "UPDATE" || next == "SHARE":
+			return "FOR " + next, 2
+		case next == "KEY" && f.upper(i+2) == "SHARE":
+			return "FOR KEY SHARE", 3
+		case next == "NO" && f.upper(i+2) == "KEY" && f.upper(i+3) == "UPDATE":
+			return "FOR NO KEY UPDATE", 4
+		}
+	case word == "JOIN":
+		return "JOIN", 1
+	case joinWords[word]:
+		n := 1
+		for joinWords[f.upper(i+n)] {
+			n++
+		}
+		if f.upper(i+n) == "JOIN" {
+			return "JOIN", n + 1
+		}
+	case word == "ON" || word == "USING":
+		if f.level().clause == "JOIN" {
+			return word, 1
+		}
+	case listClauses[word] || plainClauses[word]:
+		return word, 1
+	}
+	return "", 0
+}
+
+// hasListComma reports whether the clause starting after token i has more
+// than one top-level item.
+func (f *sqlFormatter) hasListComma(i int) bool {
+	depth := 0
+	for j := i; j < len(f.tokens); j++ {
+		switch f.tokens[j].text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+			if depth < 0 {
+				return false
+			}
+		case ";":
+			return false
+		case ",":
+			if depth == 0 {
+				return true
+			}
+		}
+		if depth == 0 && f.tokens[j].kind == tokenWord && j > i {
+			if clause, _ := f.clauseAt(j); clause != "" && clause != "ON" && clause != "USING" {
+				return false
+			}
+		}
+	}
+	return false
+}
+
+func (f *sqlFormatter) isSubquery(i int) bool {
+	for j := i + 1; j < len(f.tokens); j++ {
+		if f.tokens[j].kind == tokenLineComment || f.tokens[j].kind == tokenBlockComment {
+			continue
+		}
+		word := f.upper(j)
+		return word == "SELECT" || word == "WITH"
+	}
+	return false
+}
+
+func (f *sqlFormatter) formatToken(i int) int {
+	token := f.tokens[i]
+	level := f.level()
+
+	switch token.kind {
+	case tokenLineComment, tokenBlockComment:
+		if token.sameLine && f.line.Len() == 0 && len(f.lines) > 0 {
+			// Keep trailing comments on the line they annotate.
+			f.lines[len(f.lines)-1] += " " + token.text
+		} else {
+			f.write(token, token.text)
+		}
+		if token.kind == tokenLineComment {
+			f.pendingNewline = true
+		}
+		return i
+	case tokenWord:
+		if level.parens > 0 {
+			break
+		}
+		if clause, n := f.clauseAt(i); clause != "" {
+			return f.formatClause(clause, i, n)
+		}
+		word := strings.ToUpper(token.text)
+		if (word == "AND" || word == "OR") && level.clause != "" {
+			switch level.clause {
+			case "WHERE", "HAVING":
+				f.newline(level.indent + 1)
+			case "ON":
+				f.newline(level.indent + 2)
+			}
+		}
+		if word == "SKIP" && f.upper(i+1) == "LOCKED" && strings.HasPrefix(level.clause, "FOR ") {
+			f.writeWords(i, 2)
+			return i + 1
+		}
+		if word == "BETWEEN" {
+			// The AND of BETWEEN ... AND ... stays inline.
+			clause := level.clause
+			f.write(token, f.keyword(token.text))
+			for j := i + 1; j < len(f.tokens); j++ {
+				if f.upper(j) == "AND" {
+					f.writeWords(j, 1)
+					level.clause = clause
+					return j
+				}
+				i = f.formatToken(j)
+				j = i
+			}
+			return i
+		}
+	case tokenPunct:
+		switch token.text {
+		case "(":
+			if f.isSubquery(i) {
+				f.write(token, "(")
+				indent := f.lineIndent + 1
+				f.levels = append(f.levels, formatLevel{indent: indent, openIndent: f.lineIndent})
+				f.newline(indent)
+				return i
+			}
+			level.parens++
+		case ")":
+			if level.parens == 0 && len(f.levels) > 1 {
+				f.levels = f.levels[:len(f.levels)-1]
+				f.newline(level.openIndent)
+				f.write(token, ")")
+				return i
+			}
+			level.parens = max(level.parens-1, 0)
+		case ",":
+			f.write(token, ",")
+			if level.parens == 0 {
+				if level.clause == "WITH" {
+					f.newline(level.indent)
+				} else if level.listBreak {
+					f.newline(level.indent + 1)
+				}
+			}
+			return i
+		case ";":
+			f.write(token, ";")
+			f.levels = []formatLevel{{}}
+			f.newline(0)
+			if i+1 < len(f.tokens) {
+				f.lines = append(f.lines, "")
+			}
+			return i
+		}
+	}
+
+	text := token.text
+	if token.kind == tokenWord && sqlKeywords[strings.ToUpper(text)] {
+		text = f.keyword(text)
+	}
+	f.write(token, text)
+	return i
+}
+
+// formatClause writes a clause keyword on its own line and returns the index
+// of the last token it consumed.
+func (f *sqlFormatter) formatClause(clause string, i, n int) int {
+	level := f.level()
+	switch clause {
+	case "ON", "USING":
+		f.newline(level.indent + 1)
+		f.writeWords(i, n)
+		level.clause = "ON"
+		return i + n - 1
+	}
+
+	f.newline(level.indent)
+	f.writeWords(i, n)
+	level.clause = clause
+	level.listBreak = false
+
+	last := i + n - 1
+	if setOperations[clause] {
+		f.newline(level.indent)
+		return last
+	}
+	if listClauses[clause] {
+		// DISTINCT [ON (...)] belongs on the SELECT line.
+		if clause == "SELECT" && (f.upper(last+1) == "DISTINCT" || f.upper(last+1) == "ALL") {
+			last++
+			f.writeWords(last, 1)
+			if f.upper(last+1) == "ON" && last+2 < len(f.tokens) && f.tokens[last+2].text == "(" {
+				last++
+				f.writeWords(last, 1)
+				for depth := 0; last+1 < len(f.tokens); {
+					last++
+					token := f.tokens[last]
+					f.write(token, token.text)
+					if token.text == "(" {
+						depth++
+					} else if token.text == ")" {
+						if depth--; depth == 0 {
+							break
+						}
+					}
+				}
+			}
+		}
+		level.listBreak = f.hasListComma(last + 1)
+		if level.listBreak {
+			f.newline(level.indent + 1)
+		}
+	}
+	return last
+}
@@ -0,0 +1,239 @@
+package main
+
+import "testing"
+
+func TestFormatSQL(t *testing.T) {
+	upper := formatOptions{keywordCase: "upper", indent: "  "}
+	tests := []struct {
+		name  string
+		input string
+		opts  formatOptions
+		want  string
+	}{
+		{
+			name:  "select list and conditions",
+			input: "select id, name from users where active and age > 18 order by name",
+			opts:  upper,
+			want: `SELECT
+  id,
+  name
+FROM users
+WHERE active
+  AND age > 18
+ORDER BY name`,
+		},
+		{
+			name:  "join and between",
+			input: "select a from t1 left join t2 on t1.id = t2.t1_id and t2.x = 1 where t1.y between 1 and 5 and t1.z = 2",
+			opts:  upper,
+			want: `SELECT a
+FROM t1
+LEFT JOIN t2
+  ON t1.id = t2.t1_id
+    AND t2.x = 1
+WHERE t1.y BETWEEN 1 AND 5
+  AND t1.z = 2`,
+		},
+		{
+			name:  "cte",
+			input: "with recent as (select * from orders where created_at > now() - interval '1 day') select count(*) from recent",
+			opts:  upper,
+			want: `WITH recent AS (
+  SELECT *
+  FROM orders
+  WHERE created_at > now() - interval '1 day'
+)
+SELECT count(*)
+FROM recent`,
+		},
+		{
+			name:  "subquery",
+			input: "select * from t where id in (select id from u where u.x = 1)",
+			opts:  upper,
+			want: `SELECT *
+FROM t
+WHERE id IN (
+  SELECT id
+  FROM u
+  WHERE u.x = 1
+)`,
+		},
+		{
+			name:  "insert",
+			input: "insert into t (a, b) values (1, 'x'), (2, 'y') on conflict do nothing returning id",
+			opts:  upper,
+			want: `INSERT INTO t (a, b)
+VALUES
+  (1, 'x'),
+  (2, 'y')
+ON CONFLICT DO NOTHING
+RETURNING id`,
+		},
+		{
+			name:  "update",
+			input: "update t set a = 1, b = 2 where id = 3",
+			opts:  upper,
+			want: `UPDATE t
+SET
+  a = 1,
+  b = 2
+WHERE id = 3`,
+		},
+		{
+			name:  "trailing comment and semicolon in a string",
+			input: "select * from t -- trailing\nwhere x = 'a;b'",
+			opts:  upper,
+			want: `SELECT *
+FROM t -- trailing
+WHERE x = 'a;b'`,
+		},
+		{
+			name:  "several statements",
+			input: "select 1; select 2",
+			opts:  upper,
+			want:  "SELECT 1;\n\nSELECT 2",
+		},
+		{
+			name:  "distinct on",
+			input: "select distinct on (a) a, b from t",
+			opts:  upper,
+			want: `SELECT DISTINCT ON (a)
+  a,
+  b
+FROM t`,
+		},
+		{
+			name:  "casts and union",
+			input: "select cast(x as int), x::text from t union all select 1, 'a'",
+			opts:  upper,
+			want: `SELECT
+  CAST(x AS int),
+  x::text
+FROM t
+UNION ALL
+SELECT
+  1,
+  'a'`,
+		},
+		{
+			name:  "for update",
+			input: "select * from t where id = 1 for update",
+			opts:  upper,
+			want: `SELECT *
+FROM t
+WHERE id = 1
+FOR UPDATE`,
+		},
+		{
+			name:  "for no key update",
+			input: "select * from t for no key update of t skip locked",
+			opts:  upper,
+			want: `SELECT *
+FROM t
+FOR NO KEY UPDATE OF t SKIP LOCKED`,
+		},
+		{
+			name:  "for key share",
+			input: "select * from t for key share nowait",
+			opts:  upper,
+			want: `SELECT *
+FROM t
+FOR KEY SHARE NOWAIT`,
+		},
+		{
+			name:  "lower case",
+			input: "SELECT a FROM t WHERE b = 1",
+			opts:  formatOptions{keywordCase: "lower", indent: "\t"},
+			want:  "select a\nfrom t\nwhere b = 1",
+		},
+		{
+			name:  "preserved case",
+			input: "Select a From t",
+			opts:  formatOptions{keywordCase: "preserve", indent: "  "},
+			want:  "Select a\nFrom t",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatSQL(tt.input, tt.opts); got != tt.want {
+				t.Errorf("formatSQL(%q) =\n%s\nwant\n%s", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatStatements(t *testing.T) {
+	opts := formatOptions{keywordCase: "upper", indent: "  "}
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"select 1\n\nselect 2", "SELECT 1\n\nSELECT 2"},
+		{"select 1; select a from t;\n\n\n-- next\nselect 3", "SELECT 1;\n\nSELECT a\nFROM t;\n\n-- next\nSELECT 3"},
+		{"  \n", ""},
+	}
+	for _, tt := range tests {
+		if got := formatStatements(tt.input, opts); got != tt.want {
+			t.Errorf("formatStatements(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestTokenizeSQL(t *testing.T) {
+	input := `select 'it''s', E'a\'b', $f$x;y$f$, "Q""x", -- c
+/* b /* n */ */ 1.5e3 :: a<>b`
+	want := []struct {
+		kind sqlTokenKind
+		text string
+	}{
+		{tokenWord, "select"}, {tokenString, "'it''s'"}, {tokenPunct, ","},
+		{tokenString, `E'a\'b'`}, {tokenPunct, ","},
+		{tokenString, "$f$x;y$f$"}, {tokenPunct, ","},
+		{tokenQuotedIdent, `"Q""x"`}, {tokenPunct, ","},
+		{tokenLineComment, "-- c"}, {tokenBlockComment, "/* b /* n */ */"},
+		{tokenNumber, "1.5e3"}, {tokenOperator, "::"},
+		{tokenWord, "a"}, {tokenOperator, "<>"}, {tokenWord, "b"},
+	}
+
+	var got []sqlToken
+	for _, token := range tokenizeSQL(input) {
+		if token.kind != tokenSpace {
+			got = append(got, token)
+		}
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d tokens, want %d: %v", len(got), len(want), got)
+	}
+	for i, token := range got {
+		if token.kind != want[i].kind || token.text != want[i].text {
+			t.Errorf("token %d = %d %q, want %d %q", i, token.kind, token.text, want[i].kind, want[i].text)
+		}
+		if token.text != input[token.start:token.start+len(token.text)] {
+			t.Errorf("token %d starts at %d, which holds %q", i, token.start, input[token.start:])
+		}
+	}
+	if got[10].sameLine {
+		t.Errorf("the block comment after a line comment should start a new line")
+	}
+}
+
+func TestStatementBounds(t *testing.T) {
+	input := "select 1;\nselect ';'\n  from t;\n\nselect 3"
+	tests := []struct {
+		offset int
+		want   string
+	}{
+		{0, "select 1;"},
+		{12, "select ';'\n  from t;"},
+		{len(input) - 1, "select 3"},
+	}
+	for _, tt := range tests {
+		start, end := statementBounds(input, tt.offset)
+		if got := input[start:end]; got != tt.want {
+			t.Errorf("statementBounds(%d) = %q, want %q", tt.offset, got, tt.want)
+		}
+	}
+	if start, end := statementBounds("  ", 0); start != 0 || end != 0 {
+		t.Errorf("statementBounds of blank text = %d, %d", start, end)
+	}
+}
@@ -0,0 +1,412 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/lib/pq"
+)
+
+const progressInterval = 500 * time.Millisecond
+
+// Bloat is estimated from the share of dead tuples in the heap, which is
+// what VACUUM can give back to the table.
+const tableHealthQuery = `
+SELECT s.relname, s.n_live_tup, s.n_dead_tup, s.last_vacuum, s.last_autovacuum,
+       s.last_analyze, s.last_autoanalyze, coalesce(s.seq_scan, 0), coalesce(s.idx_scan, 0),
+       pg_size_pretty(pg_table_size(s.relid)),
+       pg_size_pretty((pg_relation_size(s.relid) * s.n_dead_tup / nullif(s.n_live_tup + s.n_dead_tup, 0))::bigint)
+FROM pg_stat_user_tables s
+WHERE s.schemaname = $1
+ORDER BY s.n_dead_tup DESC, s.relname`
+
+const indexHealthQuery = `
+SELECT s.relname, s.indexrelname, s.idx_scan, pg_size_pretty(pg_relation_size(s.indexrelid)),
+       i.indisunique OR i.indisprimary
+FROM pg_stat_user_indexes s
+JOIN pg_index i ON i.indexrelid = s.indexrelid
+WHERE s.schemaname = $1
+ORDER BY s.idx_scan, pg_relation_size(s.indexrelid) DESC, s.indexrelname`
+
+type tableHealth struct {
+	name            string
+	live, dead      int64
+	lastVacuum      sql.NullTime
+	lastAutovacuum  sql.NullTime
+	lastAnalyze     sql.NullTime
+	lastAutoanalyze sql.NullTime
+	seqScans        int64
+	idxScans        int64
+	size            string
+	bloat           sql.NullString
+}
+
+type indexHealth struct {
+	table  string
+	name   string
+	scans  int64
+	size   string
+	unique bool // unique and primary key indexes are needed even when never scanned
+}
+
+func (i indexHealth) unused() bool {
+	return i.scans == 0 && !i.unique
+}
+
+// maintenanceJob is a VACUUM or REINDEX running in the background. Its
+// progress is polled from the pg_stat_progress_* views on another connection.
+type maintenanceJob struct {
+	statement string
+	table     string
+	started   time.Time
+	phase     string
+	done      int64
+	total     int64
+}
+
+type healthDashboard struct {
+	schema     string
+	tables     []tableHealth
+	indexes    []indexHealth
+	tab        string // "tables" or "indexes"
+	cursor     int
+	confirm    *maintenanceJob // waiting for y/n
+	job        *maintenanceJob
+	generation int
+}
+
+type healthTickMsg struct {
+	generation int
+}
+
+type maintenanceDoneMsg struct {
+	statement string
+	elapsed   time.Duration
+	err       error
+}
+
+func getTableHealth(db *sql.DB, schema string) ([]tableHealth, error) {
+	rows, err := db.Query(tableHealthQuery, schema)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tables []tableHealth
+	for rows.Next() {
+		var t tableHealth
+		if err := rows.Scan(&t.name, &t.live, &t.dead, &t.lastVacuum, &t.lastAutovacuum, &t.lastAnalyze,
+			&t.lastAutoanalyze, &t.seqScans, &t.idxScans, &t.size, &t.bloat); err != nil {
+			return nil, err
+		}
+		tables = append(tables, t)
+	}
+	return tables, rows.Err()
+}
+
+func getIndexHealth(db *sql.DB, schema string) ([]indexHealth, error) {
+	rows, err := db.Query(indexHealthQuery, schema)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var indexes []indexHealth
+	for rows.Next() {
+		var i indexHealth
+		if err := rows.Scan(&i.table, &i.name, &i.scans, &i.size, &i.unique); err != nil {
+			return nil, err
+		}
+		indexes = append(indexes, i)
+	}
+	return indexes, rows.Err()
+}
+
+// healthSchema is the schema of the table the list is in or points at.
+func (m model) healthSchema() string {
+	if m.insideColumns && m.currentSchema != "" {
+		return m.currentSchema
+	}
+	if item, ok := m.dbList.SelectedItem().(dbItem); ok && item.schema != "" {
+		return item.schema
+	}
+	return "public"
+}
+
+func (m *model) openHealthDashboard() {
+	if !m.requirePostgres("The health dashboard") {
+		return
+	}
+	m.health.schema = m.healthSchema()
+	m.health.tab = "tables"
+	m.health.cursor = 0
+	m.health.confirm = nil
+	m.refreshHealth()
+	if m.queryError != "" {
+		return
+	}
+	m.showHealth = true
+	m.editor.Blur()
+}
+
+func (m *model) refreshHealth() {
+	tables, err := getTableHealth(m.db, m.health.schema)
+	if err != nil {
+		m.setQueryError(err)
+		return
+	}
+	indexes, err := getIndexHealth(m.db, m.health.schema)
+	if err != nil {
+		m.setQueryError(err)
+		return
+	}
+	m.health.tables, m.health.indexes = tables, indexes
+	m.health.cursor = clamp(m.health.cursor, 0, max(m.health.rows()-1, 0))
+}
+
+func (h healthDashboard) rows() int {
+	if h.tab == "indexes" {
+		return len(h.indexes)
+	}
+	return len(h.tables)
+}
+
+// vacuumStatement and reindexStatement act on the selected row; on the
+// indexes tab VACUUM applies to the index's table.
+func (h healthDashboard) vacuumStatement() (string, string) {
+	table := ""
+	switch {
+	case h.tab == "tables" && h.cursor < len(h.tables):
+		table = h.tables[h.cursor].name
+	case h.tab == "indexes" && h.cursor < len(h.indexes):
+		table = h.indexes[h.cursor].table
+	default:
+		return "", ""
+	}
+	return "VACUUM (ANALYZE) " + qualifiedName(h.schema, table), table
+}
+
+func (h healthDashboard) reindexStatement() (string, string) {
+	switch {
+	case h.tab == "tables" && h.cursor < len(h.tables):
+		table := h.tables[h.cursor].name
+		return "REINDEX TABLE CONCURRENTLY " + qualifiedName(h.schema, table), table
+	case h.tab == "indexes" && h.cursor < len(h.indexes):
+		index := h.indexes[h.cursor]
+		return "REINDEX INDEX CONCURRENTLY " + qualifiedName(h.schema, index.name), index.table
+	}
+	return "", ""
+}
+
+func (h healthDashboard) tick() tea.Cmd {
+	generation := h.generation
+	return tea.Tick(progressInterval, func(time.Time) tea.Msg {
+		return healthTickMsg{generation: generation}
+	})
+}
+
+// startMaintenance runs statement without blocking the UI. VACUUM and
+// REINDEX CONCURRENTLY cannot run in a transaction, so it goes through Exec.
+func (m *model) startMaintenance(statement, table string) tea.Cmd {
+	m.health.job = &maintenanceJob{statement: statement, table: table, started: time.Now()}
+	m.health.generation++
+	db := m.db
+	run := func() tea.Msg {
+		start := time.Now()
+		_, err := db.Exec(statement)
+		return maintenanceDoneMsg{statement: statement, elapsed: time.Since(start), err: err}
+	}
+	return tea.Batch(run, m.health.tick())
+}
+
+// pollProgress reads the job's progress row. VACUUM reports heap blocks in
+// pg_stat_progress_vacuum, REINDEX reports blocks in
+// pg_stat_progress_create_index; the row is gone once the command is done.
+func (m *model) pollProgress() {
+	job := m.health.job
+	relation := qualifiedName(m.health.schema, job.table)
+	var query string
+	if strings.HasPrefix(job.statement, "VACUUM") {
+		query = `SELECT phase,
+			CASE phase WHEN 'vacuuming heap' THEN heap_blks_vacuumed ELSE heap_blks_scanned END, heap_blks_total
+			FROM pg_stat_progress_vacuum WHERE relid = $1::regclass`
+	} else {
+		query = `SELECT phase, blocks_done, blocks_total
+			FROM pg_stat_progress_create_index WHERE relid = $1::regclass`
+	}
+	if err := m.db.QueryRow(query, relation).Scan(&job.phase, &job.done, &job.total); err != nil {
+		job.phase, job.done, job.total = "", 0, 0
+	}
+}
+
+func (m model) updateHealthTick(msg healthTickMsg) (tea.Model, tea.Cmd) {
+	if m.health.job == nil || msg.generation != m.health.generation {
+		return m, nil
+	}
+	if m.showHealth {
+		m.pollProgress()
+	}
+	return m, m.health.tick()
+}
+
+func (m model) finishMaintenance(msg maintenanceDoneMsg) (tea.Model, tea.Cmd) {
+	m.health.job = nil
+	m.health.generation++
+	if msg.err != nil {
+		m.setQueryError(msg.err)
+		return m, nil
+	}
+	m.statusMessage = fmt.Sprintf("%s finished in %s", msg.statement, formatDuration(msg.elapsed))
+	if m.showHealth {
+		m.refreshHealth()
+	}
+	return m, nil
+}
+
+func (m model) updateHealth(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	h := &m.health
+	if h.confirm != nil {
+		job := h.confirm
+		h.confirm = nil
+		if msg.String() == "y" {
+			return m, m.startMaintenance(job.statement, job.table)
+		}
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "esc", "q":
+		m.showHealth = false
+		m.focusState = focusEditor
+		m.dbList.SetFilteringEnabled(false)
+		m.editor.Focus()
+	case "up", "k":
+		h.cursor = max(h.cursor-1, 0)
+	case "down", "j":
+		h.cursor = min(h.cursor+1, max(h.rows()-1, 0))
+	case "g", "home":
+		h.cursor = 0
+	case "G", "end":
+		h.cursor = max(h.rows()-1, 0)
+	case "tab":
+		h.tab = cycleString([]string{"tables", "indexes"}, h.tab, 1)
+		h.cursor = 0
+	case "ctrl+r":
+		m.refreshHealth()
+	case "v":
+		if statement, table := h.vacuumStatement(); statement != "" && h.job == nil {
+			h.confirm = &maintenanceJob{statement: statement, table: table}
+		}
+	case "r":
+		if statement, table := h.reindexStatement(); statement != "" && h.job == nil {
+			h.confirm = &maintenanceJob{statement: statement, table: table}
+		}
+	case "y":
+		var name string
+		if h.tab == "tables" && h.cursor < len(h.tables) {
+			name = h.tables[h.cursor].name
+		} else if h.tab == "indexes" && h.cursor < len(h.indexes) {
+			name = h.indexes[h.cursor].name
+		}
+		if name != "" {
+			m.copyToClipboard(pq.QuoteIdentifier(name), "name")
+		}
+	}
+	return m, nil
+}
+
+// lastTime shows the later of a manual and an automatic run, marking the
+// automatic one with "auto".
+func lastTime(manual, auto sql.NullTime, now time.Time) string {
+	switch {
+	case !manual.Valid && !auto.Valid:
+		return "never"
+	case auto.Valid && (!manual.Valid || auto.Time.After(manual.Time)):
+		return formatDuration(now.Sub(auto.Time)) + " ago (auto)"
+	}
+	return formatDuration(now.Sub(manual.Time)) + " ago"
+}
+
+func (m model) healthView() string {
+	h := m.health
+	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("6"))
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+	selected := lipgloss.NewStyle().Foreground(lipgloss.Color("229")).Background(lipgloss.Color("57"))
+	warning := lipgloss.NewStyle().Foreground(lipgloss.Color("11"))
+	width := m.TotalWidth - 8
+	height := max(m.RHeight-6, 4)
+
+	unused := 0
+	for _, index := range h.indexes {
+		if index.unused() {
+			unused++
+		}
+	}
+	lines := []string{title.Render(fmt.Sprintf("Table health · %s · %d tables · %d indexes (%d unused) · showing %s",
+		h.schema, len(h.tables), len(h.indexes), unused, h.tab))}
+
+	switch {
+	case h.confirm != nil:
+		lines = append(lines, lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("196")).
+			Render(fmt.Sprintf("Run %s? (y/n)", h.confirm.statement)))
+	case h.job != nil:
+		status := fmt.Sprintf(" %s · %s", h.job.statement, formatDuration(time.Since(h.job.started)))
+		if h.job.phase != "" {
+			status += " · " + h.job.phase
+		}
+		lines = append(lines, progressBar(int(h.job.done), int(h.job.total), 30)+status)
+	default:
+		lines = append(lines, dim.Render("tab: tables/indexes · v: VACUUM (ANALYZE) · r: REINDEX CONCURRENTLY · y: copy name · ctrl+r: refresh · esc: close"))
+	}
+
+	now := time.Now()
+	var rows []string
+	if h.tab == "indexes" {
+		lines = append(lines, dim.Render(ansi.Truncate(fmt.Sprintf("%-32s %-24s %10s %10s",
+			"INDEX", "TABLE", "SCANS", "SIZE"), width, "…")))
+		for i, index := range h.indexes {
+			line := fmt.Sprintf("%-32s %-24s %10d %10s", ansi.Truncate(index.name, 32, "…"),
+				ansi.Truncate(index.table, 24, "…"), index.scans, index.size)
+			if index.unused() {
+				line += "  unused"
+			}
+			line = ansi.Truncate(line, width, "…")
+			switch {
+			case i == h.cursor:
+				line = selected.Render(line)
+			case index.unused():
+				line = warning.Render(line)
+			}
+			rows = append(rows, line)
+		}
+	} else {
+		lines = append(lines, dim.Render(ansi.Truncate(fmt.Sprintf("%-24s %10s %10s %6s %10s %10s %10s %10s  %-20s %s",
+			"TABLE", "LIVE", "DEAD", "DEAD%", "BLOAT", "SIZE", "SEQ SCAN", "IDX SCAN", "VACUUMED", "ANALYZED"), width, "…")))
+		for i, t := range h.tables {
+			deadShare := 0.0
+			if t.live+t.dead > 0 {
+				deadShare = float64(t.dead) * 100 / float64(t.live+t.dead)
+			}
+			line := fmt.Sprintf("%-24s %10d %10d %5.1f%% %10s %10s %10d %10d  %-20s %s",
+				ansi.Truncate(t.name, 24, "…"), t.live, t.dead, deadShare, t.bloat.String, t.size, t.seqScans, t.idxScans,
+				lastTime(t.lastVacuum, t.lastAutovacuum, now), lastTime(t.lastAnalyze, t.lastAutoanalyze, now))
+			line = ansi.Truncate(line, width, "…")
+			switch {
+			case i == h.cursor:
+				line = selected.Render(line)
+			case deadShare >= 20:
+				line = warning.Render(line)
+			}
+			rows = append(rows, line)
+		}
+	}
+	if len(rows) == 0 {
+		rows = append(rows, dim.Render("Nothing in this schema"))
+	}
+	return strings.Join(scrollLines(append(lines, rows...), 3, h.cursor, height), "\n")
+}
@@ -0,0 +1,783 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/lib/pq"
+)
+
+const (
+	importStepPath = iota
+	importStepPreview
+	importStepMapping
+	importStepRunning
+	importStepDone
+)
+
+const importChunkSize = 1000
+
+var (
+	importTypes       = []string{"text", "bigint", "numeric", "boolean", "date", "timestamp"}
+	identifierCleanup = regexp.MustCompile(`[^a-z0-9_]+`)
+)
+
+// csvImport is the state of the import wizard: pick a file, preview it, map
+// its columns onto a table (or a new one) and load it with COPY.
+type csvImport struct {
+	step      int
+	input     textinput.Model
+	path      string
+	delimiter rune
+	hasHeader bool
+	records   [][]string
+
+	schema      string
+	table       string // existing table selected in the browser
+	newTable    string // name of the table to create
+	createTable bool
+	columns     []dbItem // columns of the existing target table
+	mapping     []int    // CSV column for each target column, -1 to skip
+	types       []string // column types when creating the table
+	cursor      int
+
+	loaded   int
+	badRows  []importBadRow
+	err      error
+	progress chan tea.Msg
+}
+
+type importBadRow struct {
+	line   int
+	reason string
+	raw    string
+}
+
+type importRow struct {
+	line   int
+	values []interface{}
+	raw    string
+}
+
+type importProgressMsg struct {
+	done, total, bad int
+}
+
+type importDoneMsg struct {
+	loaded  int
+	badRows []importBadRow
+	err     error
+}
+
+func newCSVImport(schema, table string) csvImport {
+	input := textinput.New()
+	input.Prompt = "CSV/TSV file: "
+	input.Placeholder = "./data.csv"
+	input.Focus()
+
+	return csvImport{
+		input:     input,
+		schema:    schema,
+		table:     table,
+		hasHeader: true,
+	}
+}
+
+func (m *model) openImport() tea.Cmd {
+	if !m.requirePostgres("CSV import") {
+		return nil
+	}
+	schema, table := m.currentSchema, m.currentTable
+	if !m.insideColumns {
+		if item, ok := m.dbList.SelectedItem().(dbItem); ok && item.kind == "tables" {
+			schema, table = item.schema, item.name
+		}
+	}
+	m.importer = newCSVImport(schema, table)
+	m.showImport = true
+	m.editor.Blur()
+	return textinput.Blink
+}
+
+func (imp csvImport) header() []string {
+	if len(imp.records) == 0 {
+		return nil
+	}
+	if imp.hasHeader {
+		return imp.records[0]
+	}
+	header := make([]string, len(imp.records[0]))
+	for i := range header {
+		header[i] = fmt.Sprintf("column%d", i+1)
+	}
+	return header
+}
+
+func (imp csvImport) dataRows() [][]string {
+	if imp.hasHeader && len(imp.records) > 0 {
+		return imp.records[1:]
+	}
+	return imp.records
+}
+
+func (imp csvImport) dataLine(i int) int {
+	if imp.hasHeader {
+		return i + 2
+	}
+	return i + 1
+}
+
+func readDelimited(path string) ([][]string, rune, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	delimiter := sniffDelimiter(path, string(data))
+
+	reader := csv.NewReader(strings.NewReader(string(data)))
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	var records [][]string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		records = append(records, record)
+	}
+	if len(records) == 0 {
+		return nil, 0, fmt.Errorf("%s is empty", path)
+	}
+	return records, delimiter, nil
+}
+
+func sniffDelimiter(path, data string) rune {
+	if strings.EqualFold(filepath.Ext(path), ".tsv") {
+		return '\t'
+	}
+	firstLine, _, _ := strings.Cut(data, "\n")
+	best, bestCount := ',', 0
+	for _, candidate := range []rune{',', '\t', ';', '|'} {
+		if count := strings.Count(firstLine, string(candidate)); count > bestCount {
+			best, bestCount = candidate, count
+		}
+	}
+	return best
+}
+
+// inferColumnType picks the narrowest type every non-empty value fits.
+func inferColumnType(values []string) string {
+	fits := map[string]bool{"bigint": true, "numeric": true, "boolean": true, "date": true, "timestamp": true}
+	seen := false
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		seen = true
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			fits["bigint"] = false
+		}
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			fits["numeric"] = false
+		}
+		switch strings.ToLower(value) {
+		case "true", "false", "t", "f", "yes", "no":
+		default:
+			fits["boolean"] = false
+		}
+		if _, err := time.Parse("2006-01-02", value); err != nil {
+			fits["date"] = false
+		}
+		if !parsesAsTimestamp(value) {
+			fits["timestamp"] = false
+		}
+	}
+	if !seen {
+		return "text"
+	}
+	for _, candidate := range []string{"boolean", "bigint", "numeric", "date", "timestamp"} {
+		if fits[candidate] {
+			return candidate
+		}
+	}
+	return "text"
+}
+
+func parsesAsTimestamp(value string) bool {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01-02 15:04:05.999999"} {
+		if _, err := time.Parse(layout, value); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func identifierFromHeader(header string, position int) string {
+	name := identifierCleanup.ReplaceAllString(strings.ToLower(strings.TrimSpace(header)), "_")
+	name = strings.Trim(name, "_")
+	if name == "" {
+		return fmt.Sprintf("column%d", position+1)
+	}
+	if name[0] >= '0' && name[0] <= '9' {
+		name = "c_" + name
+	}
+	return name
+}
+
+func (m *model) loadImportFile() {
+	imp := &m.importer
+	imp.path = strings.TrimSpace(imp.input.Value())
+	records, delimiter, err := readDelimited(imp.path)
+	if err != nil {
+		m.setQueryError(err)
+		return
+	}
+	imp.records, imp.delimiter = records, delimiter
+	imp.input.Blur()
+	imp.createTable = imp.table == ""
+	imp.step = importStepPreview
+}
+
+// prepareMapping builds the default mapping for the chosen target: existing
+// columns are matched to CSV columns by name, a new table gets one column per
+// CSV column with an inferred type.
+func (m *model) prepareMapping() {
+	imp := &m.importer
+	header := imp.header()
+	imp.cursor = 0
+
+	if imp.createTable {
+		if imp.newTable == "" {
+			base := strings.TrimSuffix(filepath.Base(imp.path), filepath.Ext(imp.path))
+			imp.newTable = identifierFromHeader(base, 0)
+		}
+		if imp.schema == "" {
+			imp.schema = "public"
+		}
+		rows := imp.dataRows()
+		imp.types = make([]string, len(header))
+		for i := range header {
+			values := make([]string, 0, len(rows))
+			for _, row := range rows {
+				if i < len(row) {
+					values = append(values, row[i])
+				}
+			}
+			imp.types[i] = inferColumnType(values)
+		}
+		imp.step = importStepMapping
+		return
+	}
+
+	columns, err := m.cachedColumns(imp.schema, imp.table)
+	if err != nil {
+		m.setQueryError(err)
+		return
+	}
+	imp.columns = columns
+	imp.mapping = make([]int, len(columns))
+	for i, column := range columns {
+		imp.mapping[i] = -1
+		for j, name := range header {
+			if strings.EqualFold(strings.TrimSpace(name), column.column) ||
+				identifierFromHeader(name, j) == column.column {
+				imp.mapping[i] = j
+				break
+			}
+		}
+	}
+	imp.step = importStepMapping
+}
+
+func (imp csvImport) target() string {
+	if imp.createTable {
+		return qualifiedName(imp.schema, imp.newTable)
+	}
+	return qualifiedName(imp.schema, imp.table)
+}
+
+func (imp csvImport) targetTable() string {
+	if imp.createTable {
+		return imp.newTable
+	}
+	return imp.table
+}
+
+func (imp csvImport) targetColumns() []string {
+	header := imp.header()
+	var columns []string
+	if imp.createTable {
+		for i, name := range header {
+			columns = append(columns, identifierFromHeader(name, i))
+		}
+		return columns
+	}
+	for i, column := range imp.columns {
+		if imp.mapping[i] >= 0 {
+			columns = append(columns, column.column)
+		}
+	}
+	return columns
+}
+
+func (imp csvImport) createStatement() string {
+	header := imp.header()
+	definitions := make([]string, len(header))
+	for i, name := range header {
+		definitions[i] = pq.QuoteIdentifier(identifierFromHeader(name, i)) + " " + imp.types[i]
+	}
+	return fmt.Sprintf("CREATE TABLE %s (%s)", imp.target(), strings.Join(definitions, ", "))
+}
+
+// importRows maps every CSV record onto the target columns. Records with the
+// wrong number of fields are reported right away and never sent to the
+// server; empty fields load as NULL like COPY ... CSV does.
+func (imp csvImport) importRows() ([]importRow, []importBadRow) {
+	width := len(imp.header())
+	var rows []importRow
+	var bad []importBadRow
+
+	for i, record := range imp.dataRows() {
+		line := imp.dataLine(i)
+		raw := strings.Join(record, string(imp.delimiter))
+		if len(record) != width {
+			bad = append(bad, importBadRow{line: line, reason: fmt.Sprintf("expected %d fields, got %d", width, len(record)), raw: raw})
+			continue
+		}
+
+		var values []interface{}
+		if imp.createTable {
+			for _, field := range record {
+				values = append(values, nullIfEmpty(field))
+			}
+		} else {
+			for _, source := range imp.mapping {
+				if source >= 0 {
+					values = append(values, nullIfEmpty(record[source]))
+				}
+			}
+		}
+		rows = append(rows, importRow{line: line, values: values, raw: raw})
+	}
+	return rows, bad
+}
+
+func nullIfEmpty(field string) interface{} {
+	if field == "" {
+		return nil
+	}
+	return field
+}
+
+func (m *model) startImport() tea.Cmd {
+	imp := &m.importer
+	columns := imp.targetColumns()
+	if len(columns) == 0 {
+		m.queryError = "map at least one column"
+		return nil
+	}
+
+	ddl := ""
+	if imp.createTable {
+		ddl = imp.createStatement()
+	}
+	rows, bad := imp.importRows()
+	imp.badRows = bad
+	imp.loaded = 0
+	imp.step = importStepRunning
+	imp.progress = make(chan tea.Msg, 1)
+
+	go runImport(m.db, imp.schema, imp.targetTable(), ddl, columns, rows, bad, imp.progress)
+	return waitForImport(imp.progress)
+}
+
+func waitForImport(progress <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		return <-progress
+	}
+}
+
+// runImport loads rows in chunks of COPY FROM STDIN inside one transaction.
+// A chunk that fails is rolled back to its savepoint and retried row by row,
+// so one bad value only costs that row.
+func runImport(db *sql.DB, schema, table, ddl string, columns []string, rows []importRow, bad []importBadRow, progress chan<- tea.Msg) {
+	done := func(loaded int, err error) {
+		progress <- importDoneMsg{loaded: loaded, badRows: bad, err: err}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		done(0, err)
+		return
+	}
+	if ddl != "" {
+		if _, err := tx.Exec(ddl); err != nil {
+			tx.Rollback()
+			done(0, err)
+			return
+		}
+	}
+
+	// A failed savepoint command leaves the transaction aborted, so every
+	// later row would look bad; stop with the real cause instead.
+	savepoint := func(statement string) bool {
+		if _, err := tx.Exec(statement); err != nil {
+			tx.Rollback()
+			done(0, err)
+			return false
+		}
+		return true
+	}
+
+	loaded := 0
+	for start := 0; start < len(rows); start += importChunkSize {
+		chunk := rows[start:min(start+importChunkSize, len(rows))]
+		if !savepoint("SAVEPOINT import_chunk") {
+			return
+		}
+
+		if err := copyRows(tx, schema, table, columns, chunk); err == nil {
+			loaded += len(chunk)
+			if !savepoint("RELEASE SAVEPOINT import_chunk") {
+				return
+			}
+		} else {
+			if !savepoint("ROLLBACK TO SAVEPOINT import_chunk") {
+				return
+			}
+			for _, row := range chunk {
+				if !savepoint("SAVEPOINT import_row") {
+					return
+				}
+				if err := copyRows(tx, schema, table, columns, []importRow{row}); err != nil {
+					if !savepoint("ROLLBACK TO SAVEPOINT import_row") {
+						return
+					}
+					bad = append(bad, importBadRow{line: row.line, reason: importErrorReason(err), raw: row.raw})
+					continue
+				}
+				if !savepoint("RELEASE SAVEPOINT import_row") {
+					return
+				}
+				loaded++
+			}
+		}
+
+		progress <- importProgressMsg{done: start + len(chunk), total: len(rows), bad: len(bad)}
+	}
+
+	if err := tx.Commit(); err != nil {
+		done(0, err)
+		return
+	}
+	done(loaded, nil)
+}
+
+func copyRows(tx *sql.Tx, schema, table string, columns []string, rows []importRow) error {
+	stmt, err := tx.Prepare(pq.CopyInSchema(schema, table, columns...))
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if _, err := stmt.Exec(row.values...); err != nil {
+			stmt.Close()
+			return err
+		}
+	}
+	if _, err := stmt.Exec(); err != nil {
+		stmt.Close()
+		return err
+	}
+	return stmt.Close()
+}
+
+func importErrorReason(err error) string {
+	if pqErr, ok := err.(*pq.Error); ok {
+		return pqErr.Message
+	}
+	return err.Error()
+}
+
+// badRowsResult shows rejected rows in the results grid once the wizard
+// closes.
+func (imp csvImport) badRowsResult() resultSet {
+	rs := resultSet{
+		columns: []string{"line", "reason", "row"},
+		types:   []string{"INT4", "TEXT", "TEXT"},
+	}
+	for _, bad := range imp.badRows {
+		rs.rows = append(rs.rows, table.Row{strconv.Itoa(bad.line), bad.reason, bad.raw})
+		rs.nulls = append(rs.nulls, []bool{false, false, false})
+	}
+	return rs
+}
+
+func (m model) updateImport(msg tea.Msg) (tea.Model, tea.Cmd) {
+	imp := &m.importer
+
+	switch msg := msg.(type) {
+	case importProgressMsg:
+		imp.loaded = msg.done
+		return m, waitForImport(imp.progress)
+	case importDoneMsg:
+		imp.step = importStepDone
+		imp.loaded = msg.loaded
+		imp.badRows = msg.badRows
+		imp.err = msg.err
+		if msg.err == nil && imp.createTable {
+			return m, m.refreshMetadata()
+		}
+		return m, nil
+	case tea.KeyMsg:
+		if imp.input.Focused() {
+			switch msg.String() {
+			case "enter":
+				if imp.step == importStepPath {
+					m.loadImportFile()
+				} else {
+					imp.newTable = identifierFromHeader(imp.input.Value(), 0)
+					imp.input.Blur()
+				}
+			case "esc":
+				if imp.step == importStepPath {
+					m.closeImport()
+				}
+				imp.input.Blur()
+			default:
+				var cmd tea.Cmd
+				imp.input, cmd = imp.input.Update(msg)
+				return m, cmd
+			}
+			return m, nil
+		}
+
+		switch imp.step {
+		case importStepPreview:
+			switch msg.String() {
+			case "h":
+				imp.hasHeader = !imp.hasHeader
+			case "n":
+				imp.createTable = !imp.createTable || imp.table == ""
+			case "enter":
+				m.prepareMapping()
+			case "esc":
+				m.closeImport()
+			}
+		case importStepMapping:
+			rows := len(imp.mapping)
+			if imp.createTable {
+				rows = len(imp.types)
+			}
+			switch msg.String() {
+			case "up", "k":
+				imp.cursor = max(imp.cursor-1, 0)
+			case "down", "j":
+				imp.cursor = max(min(imp.cursor+1, rows-1), 0)
+			case "left", "right":
+				if rows == 0 {
+					break
+				}
+				step := 1
+				if msg.String() == "left" {
+					step = -1
+				}
+				if imp.createTable {
+					imp.types[imp.cursor] = cycleString(importTypes, imp.types[imp.cursor], step)
+				} else {
+					// -1 means "skip"; cycle through skip and every CSV column.
+					width := len(imp.header()) + 1
+					imp.mapping[imp.cursor] = (imp.mapping[imp.cursor]+1+step+width)%width - 1
+				}
+			case "t":
+				if imp.createTable {
+					imp.input.Prompt = "Table name: "
+					imp.input.SetValue(imp.newTable)
+					imp.input.CursorEnd()
+					return m, imp.input.Focus()
+				}
+			case "enter":
+				return m, m.startImport()
+			case "esc":
+				imp.step = importStepPreview
+			}
+		case importStepDone:
+			switch msg.String() {
+			case "enter", "esc":
+				m.closeImport()
+				if len(imp.badRows) > 0 {
+					m.browsing = false
+					m.trail = nil
+					m.setResults(imp.badRowsResult())
+					m.focusState = focusResults
+					m.editor.Blur()
+					m.resultsTable.Focus()
+				}
+			}
+		}
+	}
+	return m, nil
+}
+
+func (m *model) closeImport() {
+	m.showImport = false
+	m.focusState = focusEditor
+	m.dbList.SetFilteringEnabled(false)
+	m.editor.Focus()
+}
+
+func cycleString(values []string, current string, step int) string {
+	for i, value := range values {
+		if value == current {
+			return values[(i+step+len(values))%len(values)]
+		}
+	}
+	return values[0]
+}
+
+func (m model) importView() string {
+	imp := m.importer
+	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("6"))
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+	selected := lipgloss.NewStyle().Foreground(lipgloss.Color("229")).Background(lipgloss.Color("57"))
+	height := max(m.RHeight-6, 1)
+
+	var lines []string
+	switch imp.step {
+	case importStepPath:
+		lines = append(lines, title.Render("Import CSV/TSV (enter: open · esc: cancel)"), imp.input.View())
+	case importStepPreview:
+		delimiter := map[rune]string{',': "comma", '\t': "tab", ';': "semicolon", '|': "pipe"}[imp.delimiter]
+		target := "new table"
+		if !imp.createTable {
+			target = imp.target()
+		}
+		lines = append(lines,
+			title.Render(fmt.Sprintf("%s · %d rows · %s separated", imp.path, len(imp.dataRows()), delimiter)),
+			dim.Render(fmt.Sprintf("Target: %s · header row: %v  (h: toggle header · n: new/existing table · enter: map columns · esc: cancel)", target, imp.hasHeader)))
+		preview := append([][]string{imp.header()}, imp.dataRows()...)
+		lines = append(lines, formatPreview(preview[:min(len(preview), height-1)], m.TotalWidth-8)...)
+	case importStepMapping:
+		if imp.createTable {
+			name := imp.target()
+			if imp.input.Focused() {
+				name = imp.input.View()
+			}
+			lines = append(lines,
+				title.Render("Create "+name),
+				dim.Render("←/→: change type · t: rename table · enter: create and load · esc: back"))
+			for i, name := range imp.header() {
+				line := fmt.Sprintf("%-30s %s", identifierFromHeader(name, i), imp.types[i])
+				if i == imp.cursor {
+					line = selected.Render(line)
+				}
+				lines = append(lines, line)
+			}
+		} else {
+			lines = append(lines,
+				title.Render("Load into "+imp.target()),
+				dim.Render("←/→: choose CSV column · enter: load · esc: back"))
+			header := imp.header()
+			for i, column := range imp.columns {
+				source := dim.Render("skip")
+				if imp.mapping[i] >= 0 {
+					source = header[imp.mapping[i]]
+				}
+				line := fmt.Sprintf("%-30s ← %s", column.column+" ("+column.dataType+")", source)
+				if i == imp.cursor {
+					line = selected.Render(line)
+				}
+				lines = append(lines, line)
+			}
+		}
+		lines = scrollLines(lines, 2, imp.cursor, height)
+	case importStepRunning:
+		total := len(imp.dataRows())
+		lines = append(lines, title.Render("Loading "+imp.target()),
+			progressBar(imp.loaded, total, m.TotalWidth-30)+fmt.Sprintf(" %d/%d rows", imp.loaded, total))
+	case importStepDone:
+		if imp.err != nil {
+			lines = append(lines, title.Render("Import failed, nothing was loaded"), imp.err.Error())
+		} else {
+			lines = append(lines, title.Render(fmt.Sprintf("Loaded %d rows into %s", imp.loaded, imp.target())))
+		}
+		if len(imp.badRows) > 0 {
+			lines = append(lines, fmt.Sprintf("%d bad rows were skipped; press enter to list them", len(imp.badRows)))
+		} else {
+			lines = append(lines, dim.Render("press enter to close"))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// scrollLines keeps the fixed header lines and scrolls the rest so that the
+// cursor line stays visible.
+func scrollLines(lines []string, fixed, cursor, height int) []string {
+	body := lines[fixed:]
+	visible := max(height-fixed, 1)
+	start := 0
+	if cursor >= visible {
+		start = cursor - visible + 1
+	}
+	end := min(start+visible, len(body))
+	return append(lines[:fixed:fixed], body[start:end]...)
+}
+
+func formatPreview(records [][]string, width int) []string {
+	if len(records) == 0 {
+		return nil
+	}
+	columns := 0
+	for _, record := range records {
+		columns = max(columns, len(record))
+	}
+	widths := make([]int, columns)
+	for _, record := range records {
+		for i, field := range record {
+			widths[i] = clamp(ansi.StringWidth(field), widths[i], 20)
+		}
+	}
+
+	lines := make([]string, len(records))
+	for i, record := range records {
+		fields := make([]string, len(record))
+		for j, field := range record {
+			field = ansi.Truncate(field, widths[j], "…")
+			fields[j] = field + strings.Repeat(" ", widths[j]-ansi.StringWidth(field))
+		}
+		line := ansi.Truncate(strings.Join(fields, " │ "), width, "")
+		if i == 0 {
+			line = lipgloss.NewStyle().Bold(true).Render(line)
+		}
+		lines[i] = line
+	}
+	return lines
+}
+
+func progressBar(done, total, width int) string {
+	width = max(width, 10)
+	filled := 0
+	if total > 0 {
+		filled = done * width / total
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("5")).Render(strings.Repeat("█", filled)) +
+		lipgloss.NewStyle().Foreground(lipgloss.Color("238")).Render(strings.Repeat("░", width-filled))
+}
@@ -0,0 +1,400 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+var (
+	jsonKeyStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("14"))
+	jsonStringStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+	jsonNumberStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("11"))
+	jsonLiteralStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("13"))
+	jsonPunctStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+	xmlTagStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("14"))
+	inspectorCursor  = lipgloss.NewStyle().Foreground(lipgloss.Color("5")).Bold(true)
+
+	xmlTagPattern = regexp.MustCompile(`</?[^>]+/?>`)
+)
+
+// jsonNode is an order-preserving JSON tree; encoding/json maps would shuffle
+// object keys, which makes the pretty-printed value hard to compare with the
+// raw one.
+type jsonNode struct {
+	key       string
+	kind      byte // '{', '[' or 0 for scalars
+	value     string
+	children  []*jsonNode
+	collapsed bool
+}
+
+type inspectorLine struct {
+	text string
+	node *jsonNode
+}
+
+type cellInspector struct {
+	title    string
+	raw      string
+	pretty   string
+	root     *jsonNode
+	lines    []inspectorLine
+	cursor   int
+	sql      bool // highlight lines as SQL
+	viewport viewport.Model
+}
+
+func newCellInspector(title, value, dataType string, width, height int) cellInspector {
+	in := cellInspector{
+		title:    title,
+		raw:      value,
+		pretty:   value,
+		viewport: viewport.New(width, height),
+	}
+
+	switch {
+	case isJSONType(dataType) || looksLikeJSON(value):
+		if root, err := parseJSONTree(value); err == nil {
+			in.root = root
+			var buf bytes.Buffer
+			if json.Indent(&buf, []byte(value), "", "  ") == nil {
+				in.pretty = buf.String()
+			}
+		}
+	case strings.EqualFold(dataType, "XML") || strings.HasPrefix(strings.TrimSpace(value), "<"):
+		if pretty, err := prettyXML(value); err == nil {
+			in.pretty = pretty
+		}
+	}
+
+	in.render()
+	return in
+}
+
+func newSQLViewer(title string, statements []string, width, height int) cellInspector {
+	text := strings.Join(statements, "\n")
+	in := cellInspector{
+		title:    title,
+		raw:      text,
+		pretty:   text,
+		sql:      true,
+		viewport: viewport.New(width, height),
+	}
+	in.render()
+	return in
+}
+
+func isJSONType(dataType string) bool {
+	return strings.EqualFold(dataType, "JSON") || strings.EqualFold(dataType, "JSONB")
+}
+
+func looksLikeJSON(value string) bool {
+	value = strings.TrimSpace(value)
+	return (strings.HasPrefix(value, "{") && strings.HasSuffix(value, "}")) ||
+		(strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]"))
+}
+
+func (in *cellInspector) setSize(width, height int) {
+	in.viewport.Width = width
+	in.viewport.Height = height
+	in.render()
+}
+
+func (in *cellInspector) render() {
+	in.lines = in.lines[:0]
+	if in.root != nil {
+		in.root.render(&in.lines, 0, 0, true)
+	} else if in.sql {
+		for _, line := range strings.Split(in.pretty, "\n") {
+			in.lines = append(in.lines, inspectorLine{text: highlightSQL(line)})
+		}
+	} else if strings.HasPrefix(strings.TrimSpace(in.pretty), "<") {
+		for _, line := range strings.Split(in.pretty, "\n") {
+			in.lines = append(in.lines, inspectorLine{text: xmlTagPattern.ReplaceAllStringFunc(line, func(tag string) string {
+				return xmlTagStyle.Render(tag)
+			})})
+		}
+	} else {
+		for _, line := range strings.Split(in.pretty, "\n") {
+			in.lines = append(in.lines, inspectorLine{text: line})
+		}
+	}
+	in.cursor = clamp(in.cursor, 0, len(in.lines)-1)
+	in.refresh()
+}
+
+func (in *cellInspector) refresh() {
+	width := max(in.viewport.Width-2, 1)
+	var content []string
+	cursorTop, cursorBottom := 0, 0
+	for i, line := range in.lines {
+		gutter := "  "
+		if i == in.cursor {
+			gutter = inspectorCursor.Render("▌ ")
+			cursorTop = len(content)
+		}
+		wrapped := strings.Split(ansi.Hardwrap(line.text, width, true), "\n")
+		for j, part := range wrapped {
+			if j > 0 && i != in.cursor {
+				gutter = "  "
+			}
+			content = append(content, gutter+part)
+		}
+		if i == in.cursor {
+			cursorBottom = len(content) - 1
+		}
+	}
+	in.viewport.SetContent(strings.Join(content, "\n"))
+
+	if cursorTop < in.viewport.YOffset {
+		in.viewport.SetYOffset(cursorTop)
+	} else if cursorBottom >= in.viewport.YOffset+in.viewport.Height {
+		in.viewport.SetYOffset(cursorBottom - in.viewport.Height + 1)
+	}
+}
+
+func (in *cellInspector) moveCursor(n int) {
+	in.cursor = clamp(in.cursor+n, 0, len(in.lines)-1)
+	in.refresh()
+}
+
+func (in *cellInspector) toggle() {
+	if in.cursor >= len(in.lines) {
+		return
+	}
+	node := in.lines[in.cursor].node
+	if node == nil || node.kind == 0 || len(node.children) == 0 {
+		return
+	}
+	node.collapsed = !node.collapsed
+	in.render()
+
+	// Keep the cursor on the node's opening line when collapsing from the
+	// closing bracket.
+	for i, line := range in.lines {
+		if line.node == node {
+			in.cursor = i
+			break
+		}
+	}
+	in.refresh()
+}
+
+func (in *cellInspector) setCollapsedAll(collapsed bool) {
+	if in.root == nil {
+		return
+	}
+	var walk func(n *jsonNode, depth int)
+	walk = func(n *jsonNode, depth int) {
+		if n.kind != 0 {
+			n.collapsed = collapsed && depth > 0
+		}
+		for _, child := range n.children {
+			walk(child, depth+1)
+		}
+	}
+	walk(in.root, 0)
+	in.cursor = 0
+	in.render()
+}
+
+func (in cellInspector) View() string {
+	header := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("6")).Render(in.title)
+	return lipgloss.JoinVertical(lipgloss.Left, header, in.viewport.View())
+}
+
+func parseJSONTree(s string) (*jsonNode, error) {
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+	root, err := decodeJSONNode(dec)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("unexpected data after JSON value")
+	}
+	return root, nil
+}
+
+func decodeJSONNode(dec *json.Decoder) (*jsonNode, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := tok.(type) {
+	case json.Delim:
+		node := &jsonNode{kind: byte(t)}
+		for dec.More() {
+			key := ""
+			if t == '{' {
+				keyTok, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				key, _ = keyTok.(string)
+			}
+			child, err := decodeJSONNode(dec)
+			if err != nil {
+				return nil, err
+			}
+			child.key = key
+			node.children = append(node.children, child)
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return node, nil
+	case string:
+		return &jsonNode{value: quoteJSON(t)}, nil
+	case json.Number:
+		return &jsonNode{value: t.String()}, nil
+	case bool:
+		return &jsonNode{value: strconv.FormatBool(t)}, nil
+	default:
+		return &jsonNode{value: "null"}, nil
+	}
+}
+
+func quoteJSON(s string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+func (n *jsonNode) render(lines *[]inspectorLine, indent int, parent byte, last bool) {
+	prefix := strings.Repeat("  ", indent)
+	if parent == '{' {
+		prefix += jsonKeyStyle.Render(quoteJSON(n.key)) + jsonPunctStyle.Render(": ")
+	}
+	comma := ""
+	if !last {
+		comma = jsonPunctStyle.Render(",")
+	}
+
+	if n.kind == 0 {
+		*lines = append(*lines, inspectorLine{text: prefix + n.styledValue() + comma, node: n})
+		return
+	}
+
+	open, closing := "{", "}"
+	unit := "keys"
+	if n.kind == '[' {
+		open, closing = "[", "]"
+		unit = "items"
+	}
+
+	if len(n.children) == 0 {
+		*lines = append(*lines, inspectorLine{text: prefix + jsonPunctStyle.Render(open+closing) + comma, node: n})
+		return
+	}
+	if n.collapsed {
+		summary := jsonPunctStyle.Render(open+"…"+closing) + comma +
+			jsonPunctStyle.Render(" "+strconv.Itoa(len(n.children))+" "+unit)
+		*lines = append(*lines, inspectorLine{text: prefix + summary, node: n})
+		return
+	}
+
+	*lines = append(*lines, inspectorLine{text: prefix + jsonPunctStyle.Render(open), node: n})
+	for i, child := range n.children {
+		child.render(lines, indent+1, n.kind, i == len(n.children)-1)
+	}
+	*lines = append(*lines, inspectorLine{
+		text: strings.Repeat("  ", indent) + jsonPunctStyle.Render(closing) + comma,
+		node: n,
+	})
+}
+
+func (n *jsonNode) styledValue() string {
+	switch {
+	case strings.HasPrefix(n.value, `"`):
+		return jsonStringStyle.Render(n.value)
+	case n.value == "true" || n.value == "false" || n.value == "null":
+		return jsonLiteralStyle.Render(n.value)
+	default:
+		return jsonNumberStyle.Render(n.value)
+	}
+}
+
+func prettyXML(s string) (string, error) {
+	dec := xml.NewDecoder(strings.NewReader(s))
+	var buf bytes.Buffer
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		if data, ok := tok.(xml.CharData); ok && strings.TrimSpace(string(data)) == "" {
+			continue
+		}
+		if err := enc.EncodeToken(xml.CopyToken(tok)); err != nil {
+			return "", err
+		}
+	}
+	if err := enc.Flush(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func (m *model) openInspector() {
+	col := m.selectedColumn()
+	value, ok := m.result.cell(m.resultsTable.Cursor(), col)
+	if !ok {
+		return
+	}
+	title := fmt.Sprintf("%s · row %d (%s)", m.result.columns[col],
+		m.resultsTable.Cursor()+1, strings.ToLower(m.result.types[col]))
+	m.inspector = newCellInspector(title, value, m.result.types[col], m.TotalWidth-6, m.RHeight-5)
+	m.showInspector = true
+}
+
+func (m model) updateInspector(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		m.showInspector = false
+	case "up", "k":
+		m.inspector.moveCursor(-1)
+	case "down", "j":
+		m.inspector.moveCursor(1)
+	case "pgup", "b":
+		m.inspector.moveCursor(-m.inspector.viewport.Height)
+	case "pgdown", "f":
+		m.inspector.moveCursor(m.inspector.viewport.Height)
+	case "home", "g":
+		m.inspector.moveCursor(-len(m.inspector.lines))
+	case "end", "G":
+		m.inspector.moveCursor(len(m.inspector.lines))
+	case "enter", " ":
+		m.inspector.toggle()
+	case "z":
+		m.inspector.setCollapsedAll(true)
+	case "e":
+		m.inspector.setCollapsedAll(false)
+	case "y":
+		m.copyToClipboard(m.inspector.pretty, "value")
+	case "Y":
+		m.copyToClipboard(m.inspector.raw, "raw value")
+	}
+	return m, nil
+}
@@ -0,0 +1,324 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/lib/pq"
+)
+
+const maxNotifications = 1000
+
+type notification struct {
+	at      time.Time
+	channel string
+	payload string
+	pid     int
+	event   string // set instead of channel for listener state changes
+}
+
+// notificationMsg carries the listener it came from, so messages from a
+// listener replaced by a database switch are dropped.
+type notificationMsg struct {
+	listener     *pq.Listener
+	notification *pq.Notification
+	err          error // the connection failed; lib/pq keeps retrying
+	closed       bool
+}
+
+// notifyMonitor is the LISTEN/NOTIFY panel. The listener keeps its own
+// connection and stays subscribed while the panel is closed.
+type notifyMonitor struct {
+	listener *pq.Listener
+	errs     chan error // connection errors reported by the listener
+	channels []string
+	events   []notification
+	cursor   int
+	unread   int
+	input    textinput.Model
+	editing  string // "listen", "unlisten" or "notify" while the input is focused
+}
+
+func newNotifyMonitor() notifyMonitor {
+	input := textinput.New()
+	input.CharLimit = 8000
+	return notifyMonitor{input: input}
+}
+
+func (m *model) openNotifyMonitor() tea.Cmd {
+	if !m.requirePostgres("LISTEN/NOTIFY") {
+		return nil
+	}
+	m.notify.unread = 0
+	m.showNotify = true
+	m.editor.Blur()
+	if len(m.notify.channels) == 0 {
+		return m.notify.prompt("listen", "")
+	}
+	return nil
+}
+
+func (n *notifyMonitor) prompt(editing, value string) tea.Cmd {
+	n.editing = editing
+	n.input.Prompt = map[string]string{
+		"listen":   "LISTEN ",
+		"unlisten": "UNLISTEN ",
+		"notify":   "NOTIFY channel, payload: ",
+	}[editing]
+	n.input.SetValue(value)
+	n.input.CursorEnd()
+	return n.input.Focus()
+}
+
+func waitForNotification(listener *pq.Listener, errs chan error) tea.Cmd {
+	return func() tea.Msg {
+		select {
+		case n, ok := <-listener.Notify:
+			return notificationMsg{listener: listener, notification: n, closed: !ok}
+		case err := <-errs:
+			return notificationMsg{listener: listener, err: err}
+		}
+	}
+}
+
+// listenerErrors hands the connection errors of a listener to the UI,
+// dropping them while it is behind.
+func listenerErrors(errs chan error) pq.EventCallbackType {
+	return func(_ pq.ListenerEventType, err error) {
+		if err == nil {
+			return
+		}
+		select {
+		case errs <- err:
+		default:
+		}
+	}
+}
+
+func (m *model) listen(channel string) tea.Cmd {
+	var cmd tea.Cmd
+	if m.notify.listener == nil {
+		m.notify.errs = make(chan error, 1)
+		m.notify.listener = pq.NewListener(activeDSN, time.Second, time.Minute, listenerErrors(m.notify.errs))
+		cmd = waitForNotification(m.notify.listener, m.notify.errs)
+	}
+	if err := m.notify.listener.Listen(channel); err != nil && err != pq.ErrChannelAlreadyOpen {
+		m.setQueryError(err)
+		return cmd
+	}
+	if !containsString(m.notify.channels, channel) {
+		m.notify.channels = append(m.notify.channels, channel)
+	}
+	return cmd
+}
+
+func (m *model) unlisten(channel string) {
+	if m.notify.listener == nil {
+		return
+	}
+	if err := m.notify.listener.Unlisten(channel); err != nil {
+		m.setQueryError(err)
+		return
+	}
+	var channels []string
+	for _, c := range m.notify.channels {
+		if c != channel {
+			channels = append(channels, c)
+		}
+	}
+	m.notify.channels = channels
+}
+
+// sendNotify parses "channel, payload" and sends it through pg_notify so the
+// payload needs no quoting.
+func (m *model) sendNotify(value string) {
+	channel, payload, _ := strings.Cut(value, ",")
+	channel = strings.TrimSpace(channel)
+	if channel == "" {
+		return
+	}
+	if _, err := m.db.Exec("SELECT pg_notify($1, $2)", channel, strings.TrimSpace(payload)); err != nil {
+		m.setQueryError(err)
+		return
+	}
+	m.statusMessage = "Sent NOTIFY on " + channel
+}
+
+func (m *model) addNotification(msg notificationMsg) tea.Cmd {
+	n := &m.notify
+	if msg.listener != n.listener {
+		return nil
+	}
+	if msg.closed {
+		n.listener = nil
+		n.channels = nil
+		return nil
+	}
+	if msg.err != nil {
+		m.statusMessage = "LISTEN connection: " + msg.err.Error()
+		return waitForNotification(n.listener, n.errs)
+	}
+
+	event := notification{at: time.Now()}
+	if msg.notification == nil {
+		// lib/pq sends nil after re-establishing the connection.
+		event.event = "reconnected; notifications sent meanwhile are lost"
+	} else {
+		event.channel = msg.notification.Channel
+		event.payload = msg.notification.Extra
+		event.pid = msg.notification.BePid
+	}
+
+	atEnd := n.cursor >= len(n.events)-1
+	n.events = append(n.events, event)
+	if len(n.events) > maxNotifications {
+		n.events = n.events[len(n.events)-maxNotifications:]
+		n.cursor = max(n.cursor-1, 0)
+	}
+	if atEnd {
+		n.cursor = len(n.events) - 1
+	}
+	if !m.showNotify {
+		n.unread++
+	}
+	return waitForNotification(n.listener, n.errs)
+}
+
+func (m model) updateNotify(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	n := &m.notify
+	if n.input.Focused() {
+		switch msg.String() {
+		case "enter":
+			value := strings.TrimSpace(n.input.Value())
+			n.input.Blur()
+			if value == "" {
+				return m, nil
+			}
+			switch n.editing {
+			case "listen":
+				return m, m.listen(value)
+			case "unlisten":
+				m.unlisten(value)
+			case "notify":
+				m.sendNotify(value)
+			}
+		case "esc":
+			n.input.Blur()
+		default:
+			var cmd tea.Cmd
+			n.input, cmd = n.input.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "esc", "q":
+		m.showNotify = false
+		m.focusState = focusEditor
+		m.dbList.SetFilteringEnabled(false)
+		m.editor.Focus()
+	case "up", "k":
+		n.cursor = max(n.cursor-1, 0)
+	case "down", "j":
+		n.cursor = min(n.cursor+1, len(n.events)-1)
+	case "g", "home":
+		n.cursor = 0
+	case "G", "end":
+		n.cursor = len(n.events) - 1
+	case "l":
+		return m, n.prompt("listen", "")
+	case "u":
+		last := ""
+		if len(n.channels) > 0 {
+			last = n.channels[len(n.channels)-1]
+		}
+		return m, n.prompt("unlisten", last)
+	case "s":
+		channel := ""
+		if n.cursor < len(n.events) && n.events[n.cursor].channel != "" {
+			channel = n.events[n.cursor].channel
+		} else if len(n.channels) > 0 {
+			channel = n.channels[0]
+		}
+		if channel != "" {
+			channel += ", "
+		}
+		return m, n.prompt("notify", channel)
+	case "c":
+		n.events = nil
+		n.cursor = 0
+	case "y":
+		if n.cursor < len(n.events) {
+			m.copyToClipboard(n.events[n.cursor].payload, "payload")
+		}
+	}
+	return m, nil
+}
+
+// prettyPayload indents JSON payloads and leaves anything else as it is.
+func prettyPayload(payload string) (string, bool) {
+	var out bytes.Buffer
+	if json.Valid([]byte(payload)) && json.Indent(&out, []byte(payload), "", "  ") == nil {
+		return out.String(), true
+	}
+	return payload, false
+}
+
+func (m model) notifyView() string {
+	n := m.notify
+	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("6"))
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+	selected := lipgloss.NewStyle().Foreground(lipgloss.Color("229")).Background(lipgloss.Color("57"))
+	width := m.TotalWidth - 8
+	height := max(m.RHeight-6, 3)
+
+	channels := dim.Render("not listening")
+	if len(n.channels) > 0 {
+		channels = strings.Join(n.channels, ", ")
+	}
+	lines := []string{title.Render("LISTEN/NOTIFY ") + channels}
+	if n.input.Focused() {
+		lines = append(lines, n.input.View())
+	} else {
+		lines = append(lines, dim.Render("l: listen · u: unlisten · s: send NOTIFY · y: copy payload · c: clear · esc: close"))
+	}
+	if len(n.events) == 0 {
+		return strings.Join(append(lines, dim.Render("Waiting for notifications…")), "\n")
+	}
+
+	// A JSON payload of the selected notification is shown pretty-printed
+	// below the list.
+	var detail []string
+	if n.cursor < len(n.events) {
+		if pretty, isJSON := prettyPayload(n.events[n.cursor].payload); isJSON && strings.Contains(pretty, "\n") {
+			detail = strings.Split(pretty, "\n")
+			detail = detail[:min(len(detail), height/2)]
+		}
+	}
+
+	var list []string
+	for i, event := range n.events {
+		line := fmt.Sprintf("%s  %-20s %7d  %s", event.at.Format("15:04:05.000"), event.channel, event.pid,
+			strings.ReplaceAll(event.payload, "\n", " "))
+		if event.event != "" {
+			line = event.at.Format("15:04:05.000") + "  " + event.event
+		}
+		line = ansi.Truncate(line, width, "…")
+		if i == n.cursor {
+			line = selected.Render(line)
+		} else if event.event != "" {
+			line = dim.Render(line)
+		}
+		list = append(list, line)
+	}
+	lines = scrollLines(append(lines, list...), 2, n.cursor, height-len(detail))
+	return strings.Join(append(lines, detail...), "\n")
+}
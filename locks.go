@@ -0,0 +1,340 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/lib/pq"
+)
+
+const lockQuery = `
+SELECT a.pid, pg_blocking_pids(a.pid), coalesce(a.usename, ''), coalesce(a.state, ''),
+       a.query_start, coalesce(a.query, ''),
+       coalesce((SELECT string_agg(DISTINCT l.mode || coalesce(' on ' || l.relation::regclass::text, ' (' || l.locktype || ')'), ', ')
+                 FROM pg_locks l WHERE l.pid = a.pid AND NOT l.granted), ''),
+       coalesce((SELECT string_agg(DISTINCT l.mode || ' on ' || l.relation::regclass::text, ', ')
+                 FROM pg_locks l WHERE l.pid = a.pid AND l.granted AND l.relation IS NOT NULL), '')
+FROM pg_stat_activity a
+WHERE a.pid <> pg_backend_pid()`
+
+type lockedBackend struct {
+	pid        int
+	blockedBy  []int
+	user       string
+	state      string
+	queryStart sql.NullTime
+	query      string
+	waitingFor string // lock modes the backend waits for
+	holding    string // relation locks it holds
+}
+
+// lockNode is one line of a blocking tree: a backend drawn under the
+// backend blocking it.
+type lockNode struct {
+	pid    int
+	depth  int
+	parent int
+	root   int
+	last   []bool // whether the node and each ancestor is the last sibling, for the tree lines
+}
+
+type lockViewer struct {
+	backends   map[int]lockedBackend
+	nodes      []lockNode
+	cursor     int
+	refreshed  time.Time
+	generation int
+}
+
+type lockTickMsg struct {
+	generation int
+}
+
+func getLockedBackends(db *sql.DB) (map[int]lockedBackend, error) {
+	rows, err := db.Query(lockQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	backends := map[int]lockedBackend{}
+	for rows.Next() {
+		var b lockedBackend
+		var blockedBy []int64
+		if err := rows.Scan(&b.pid, pq.Array(&blockedBy), &b.user, &b.state, &b.queryStart,
+			&b.query, &b.waitingFor, &b.holding); err != nil {
+			return nil, err
+		}
+		for _, pid := range blockedBy {
+			b.blockedBy = append(b.blockedBy, int(pid))
+		}
+		backends[b.pid] = b
+	}
+	return backends, rows.Err()
+}
+
+// blockingTrees lays out every blocking chain as a tree rooted at a backend
+// that is not blocked itself. Backends stuck in a cycle get a root of their
+// own so they still show up.
+func blockingTrees(backends map[int]lockedBackend) []lockNode {
+	blocks := map[int][]int{}
+	for pid, b := range backends {
+		for _, blocker := range b.blockedBy {
+			blocks[blocker] = append(blocks[blocker], pid)
+		}
+	}
+	for _, blocked := range blocks {
+		sort.Ints(blocked)
+	}
+
+	var roots []int
+	for pid := range blocks {
+		if len(backends[pid].blockedBy) == 0 {
+			roots = append(roots, pid)
+		}
+	}
+	sort.Ints(roots)
+
+	var nodes []lockNode
+	shown := map[int]bool{}
+	var walk func(pid, parent, root int, last []bool, path map[int]bool)
+	walk = func(pid, parent, root int, last []bool, path map[int]bool) {
+		nodes = append(nodes, lockNode{pid: pid, depth: len(last), parent: parent, root: root, last: last})
+		shown[pid] = true
+		path[pid] = true
+		children := blocks[pid]
+		for i, child := range children {
+			if path[child] {
+				continue
+			}
+			walk(child, pid, root, append(append([]bool{}, last...), i == len(children)-1), path)
+		}
+		delete(path, pid)
+	}
+	for _, root := range roots {
+		walk(root, 0, root, nil, map[int]bool{})
+	}
+
+	var cycles []int
+	for pid, b := range backends {
+		if len(b.blockedBy) > 0 && !shown[pid] {
+			cycles = append(cycles, pid)
+		}
+	}
+	sort.Ints(cycles)
+	for _, pid := range cycles {
+		if !shown[pid] {
+			walk(pid, 0, pid, nil, map[int]bool{})
+		}
+	}
+	return nodes
+}
+
+func (v lockViewer) tick() tea.Cmd {
+	generation := v.generation
+	return tea.Tick(activityInterval, func(time.Time) tea.Msg {
+		return lockTickMsg{generation: generation}
+	})
+}
+
+func (m *model) openLockViewer() tea.Cmd {
+	if !m.requirePostgres("The lock viewer") {
+		return nil
+	}
+	m.locks.generation++
+	m.showLocks = true
+	m.editor.Blur()
+	m.refreshLocks()
+	return m.locks.tick()
+}
+
+func (m *model) refreshLocks() {
+	backends, err := getLockedBackends(m.db)
+	if err != nil {
+		m.setQueryError(err)
+		return
+	}
+	pid := m.locks.selectedPID()
+	m.locks.backends = backends
+	m.locks.nodes = blockingTrees(backends)
+	m.locks.refreshed = time.Now()
+	for i, node := range m.locks.nodes {
+		if node.pid == pid {
+			m.locks.cursor = i
+			return
+		}
+	}
+	m.locks.cursor = clamp(m.locks.cursor, 0, max(len(m.locks.nodes)-1, 0))
+}
+
+func (m model) updateLockTick(msg lockTickMsg) (tea.Model, tea.Cmd) {
+	if !m.showLocks || msg.generation != m.locks.generation {
+		return m, nil
+	}
+	m.refreshLocks()
+	return m, m.locks.tick()
+}
+
+func (v lockViewer) waiting() int {
+	waiting := 0
+	for _, b := range v.backends {
+		if len(b.blockedBy) > 0 {
+			waiting++
+		}
+	}
+	return waiting
+}
+
+func (v lockViewer) selectedPID() int {
+	if v.cursor < len(v.nodes) {
+		return v.nodes[v.cursor].pid
+	}
+	return 0
+}
+
+// chain follows the first blocker of pid up to the root: blocked → blocker →
+// … → root blocker.
+func (v lockViewer) chain(pid int) []int {
+	chain := []int{pid}
+	seen := map[int]bool{pid: true}
+	for {
+		blockers := v.backends[pid].blockedBy
+		if len(blockers) == 0 || seen[blockers[0]] {
+			return chain
+		}
+		pid = blockers[0]
+		seen[pid] = true
+		chain = append(chain, pid)
+	}
+}
+
+// showInActivity closes the lock viewer and selects pid in the activity
+// monitor.
+func (m *model) showInActivity(pid int) tea.Cmd {
+	m.showLocks = false
+	m.locks.generation++
+	m.activity.filter.SetValue("")
+	cmd := m.openActivityMonitor()
+	m.activity.selectPID(pid)
+	return cmd
+}
+
+func (m model) updateLocks(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	v := &m.locks
+	switch msg.String() {
+	case "esc", "q":
+		m.showLocks = false
+		v.generation++
+		m.focusState = focusEditor
+		m.dbList.SetFilteringEnabled(false)
+		m.editor.Focus()
+	case "up", "k":
+		v.cursor = max(v.cursor-1, 0)
+	case "down", "j":
+		v.cursor = min(v.cursor+1, max(len(v.nodes)-1, 0))
+	case "g", "home":
+		v.cursor = 0
+	case "G", "end":
+		v.cursor = max(len(v.nodes)-1, 0)
+	case "ctrl+r":
+		m.refreshLocks()
+	case "enter", "a":
+		if pid := v.selectedPID(); pid != 0 {
+			return m, m.showInActivity(pid)
+		}
+	case "b":
+		if v.cursor < len(v.nodes) && v.nodes[v.cursor].parent != 0 {
+			return m, m.showInActivity(v.nodes[v.cursor].parent)
+		}
+	case "r":
+		if v.cursor < len(v.nodes) {
+			return m, m.showInActivity(v.nodes[v.cursor].root)
+		}
+	}
+	return m, nil
+}
+
+func treePrefix(last []bool) string {
+	var prefix strings.Builder
+	for i, isLast := range last {
+		switch {
+		case i < len(last)-1 && isLast:
+			prefix.WriteString("   ")
+		case i < len(last)-1:
+			prefix.WriteString("│  ")
+		case isLast:
+			prefix.WriteString("└─ ")
+		default:
+			prefix.WriteString("├─ ")
+		}
+	}
+	return prefix.String()
+}
+
+func (m model) lockView() string {
+	v := m.locks
+	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("6"))
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+	selected := lipgloss.NewStyle().Foreground(lipgloss.Color("229")).Background(lipgloss.Color("57"))
+	blocker := lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true)
+	width := m.TotalWidth - 8
+	height := max(m.RHeight-6, 4)
+
+	lines := []string{
+		title.Render(fmt.Sprintf("Blocking chains · %d backends waiting · %s", v.waiting(), v.refreshed.Format("15:04:05"))),
+		dim.Render("enter/a: show in activity · b: its blocker · r: root blocker · ctrl+r: refresh · esc: close"),
+	}
+	if len(v.nodes) == 0 {
+		return strings.Join(append(lines, dim.Render("No backend is waiting for a lock")), "\n")
+	}
+
+	now := time.Now()
+	var rows []string
+	for i, node := range v.nodes {
+		b := v.backends[node.pid]
+		locks := "holds " + b.holding
+		if len(b.blockedBy) > 0 {
+			locks = "waits for " + b.waitingFor
+		}
+		duration := ""
+		if b.queryStart.Valid {
+			duration = formatDuration(now.Sub(b.queryStart.Time))
+		}
+		line := fmt.Sprintf("%s%d  %s · %s · %s · %s", treePrefix(node.last), node.pid, b.user, b.state, duration, locks)
+		line = ansi.Truncate(line+"  "+strings.Join(strings.Fields(b.query), " "), width, "…")
+		switch {
+		case i == v.cursor:
+			line = selected.Render(line)
+		case node.depth == 0:
+			line = blocker.Render(line)
+		}
+		rows = append(rows, line)
+	}
+
+	var detail []string
+	if pid := v.selectedPID(); pid != 0 {
+		chain := v.chain(pid)
+		pids := make([]string, len(chain))
+		for i, p := range chain {
+			pids[i] = strconv.Itoa(p)
+		}
+		label := "blocked → blocker → root"
+		if len(chain) == 1 {
+			label = "root blocker"
+		}
+		detail = append(detail, dim.Render(label+": ")+strings.Join(pids, " → "))
+		if query := v.backends[pid].query; query != "" {
+			wrapped := strings.Split(ansi.Hardwrap(strings.Join(strings.Fields(query), " "), width, true), "\n")
+			detail = append(detail, wrapped[:min(len(wrapped), height/3)]...)
+		}
+	}
+	lines = scrollLines(append(lines, rows...), 2, v.cursor, height-len(detail))
+	return strings.Join(append(lines, detail...), "\n")
+}
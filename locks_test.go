@@ -0,0 +1,29 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBlockingTrees(t *testing.T) {
+	// 1 blocks 2 and 4, 2 blocks 3; 5 and 6 wait on each other.
+	backends := map[int]lockedBackend{
+		1: {pid: 1},
+		2: {pid: 2, blockedBy: []int{1}},
+		3: {pid: 3, blockedBy: []int{2}},
+		4: {pid: 4, blockedBy: []int{1}},
+		5: {pid: 5, blockedBy: []int{6}},
+		6: {pid: 6, blockedBy: []int{5}},
+	}
+	want := []lockNode{
+		{pid: 1, depth: 0, parent: 0, root: 1},
+		{pid: 2, depth: 1, parent: 1, root: 1, last: []bool{false}},
+		{pid: 3, depth: 2, parent: 2, root: 1, last: []bool{false, true}},
+		{pid: 4, depth: 1, parent: 1, root: 1, last: []bool{true}},
+		{pid: 5, depth: 0, parent: 0, root: 5},
+		{pid: 6, depth: 1, parent: 5, root: 5, last: []bool{true}},
+	}
+	if got := blockingTrees(backends); !reflect.DeepEqual(got, want) {
+		t.Errorf("blockingTrees() =\n%+v\nwant\n%+v", got, want)
+	}
+}
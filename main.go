@@ -9,12 +9,14 @@ import (
 	"github.com/muesli/termenv"
 	"log"
 	"strings"
+	"sync/atomic"
 
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 const (
@@ -24,32 +26,95 @@ const (
 )
 
 type dbItem struct {
-	name  string
-	kind  string // "db", "table", "column"
-	child []dbItem
+	name     string
+	kind     string // "db", "tables", "column", "settings", "setting", "roles", "role"
+	schema   string
+	table    string // Owning table of a column
+	column   string // Column name without the type shown in name
+	dataType string
+	detail   string // Shown under the name instead of the kind
+	child    []dbItem
 }
 
-func (i dbItem) Title() string       { return i.name }
-func (i dbItem) Description() string { return i.kind }
+func (i dbItem) Title() string { return i.name }
+func (i dbItem) Description() string {
+	if i.detail != "" {
+		return i.detail
+	}
+	return i.kind
+}
 func (i dbItem) FilterValue() string { return i.name }
 
 type model struct {
 	dbList        list.Model
 	editor        textarea.Model
 	db            *sql.DB
-	data          []dbItem // Loaded databases
+	metadata      metadataCache
 	insideColumns bool
-	resultWindow  bool // Indicates if the results window is displayed
+	listParents   []dbItem // Items entered from the top of the list, innermost last
+	resultWindow  bool     // Indicates if the results window is displayed
 	queryError    string
 	queryResult   []string // Query results
 	currentPage   int      // Current page of the results
 	itemsPerPage  int      // Number of rows per page
 	focusedEditor bool     // Indicates if the focus is on the editor
 	currentTable  string   // Name of the current table
-
-	resultsTable table.Model
-	showResults  bool
-	focusState   int
+	currentSchema string   // Schema of the current table
+
+	resultsTable     table.Model
+	showResults      bool
+	focusState       int
+	result           resultSet // Full result behind resultsTable
+	colCursor        int       // Selected visible column in the results grid
+	grid             gridLayout
+	showColumnPicker bool
+	pickerCursor     int
+	inspector        cellInspector
+	showInspector    bool
+	statusMessage    string
+	browser          tableBrowser
+	browsing         bool // Results come from the table data browser
+	edits            editSession
+	review           cellInspector
+	showReview       bool
+	trail            []resultCrumb // Results left by following foreign keys
+	fkReferences     []fkReference
+	fkCursor         int
+	showFKPicker     bool
+	importer         csvImport
+	showImport       bool
+	marked           map[int]bool // Result rows marked for export
+	export           insertExport
+	showExport       bool
+	pgError          *pq.Error // Details behind queryError, if it came from the server
+	errorMark        errorMark
+	showErrorPanel   bool
+	notices          []serverNotice
+	noticeCh         chan serverNotice
+	noticeDrops      *atomic.Int64 // notices the handler had no room for, not yet counted
+	droppedNotices   int
+	unreadNotices    int
+	showMessages     bool
+	messages         viewport.Model
+	notify           notifyMonitor
+	showNotify       bool
+	watch            watchState
+	activity         activityMonitor
+	showActivity     bool
+	locks            lockViewer
+	showLocks        bool
+	statements       statementInsights
+	showStatements   bool
+	health           healthDashboard
+	showHealth       bool
+	finder           objectFinder
+	showFinder       bool
+	profiler         columnProfiler
+	showProfile      bool
+	chart            resultChart
+	showChart        bool
+	er               erDiagram
+	showER           bool
 
 	LWidth     int
 	EWidth     int
@@ -59,21 +124,12 @@ type model struct {
 }
 
 func initialModel() model {
-	db, err := connectToPostgres()
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	dbs, err := getTables(db)
+	notices, drops := make(chan serverNotice, 256), &atomic.Int64{}
+	db, err := openDatabase(noticeHandler(notices, drops))
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	items := make([]list.Item, len(dbs))
-	for i, db := range dbs {
-		items[i] = db
-	}
-
 	InitBackupSystems()
 	editor := setupTextarea()
 	backup, err := loadEditorBackup()
@@ -83,18 +139,25 @@ func initialModel() model {
 
 	tbl := setupTable()
 	return model{
-		dbList:       list.New(items, list.NewDefaultDelegate(), 0, 0),
+		dbList:       list.New(nil, list.NewDefaultDelegate(), 0, 0),
 		editor:       editor,
 		db:           db,
-		data:         dbs,
+		metadata:     newMetadataCache(),
 		itemsPerPage: 10,
 		resultsTable: tbl,
 		focusState:   focusEditor,
+		noticeCh:     notices,
+		noticeDrops:  drops,
+		messages:     viewport.New(0, 0),
+		notify:       newNotifyMonitor(),
+		activity:     newActivityMonitor(),
+		statements:   newStatementInsights(),
+		finder:       newObjectFinder(),
 	}
 }
 
 func (m model) Init() tea.Cmd {
-	return textinput.Blink
+	return tea.Batch(textinput.Blink, waitForNotice(m.noticeCh), m.loadMetadata(metadataKey{}))
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -110,18 +173,168 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.RHeight = totalHeight - m.MainHeight - 3
 
 		m.reDrawTable()
+		if m.showResults {
+			m.layoutResults()
+		}
 
 		m.dbList.SetSize(m.LWidth, m.MainHeight-4)
 		m.editor.SetWidth(m.EWidth)
 		m.editor.SetHeight(m.MainHeight)
+		m.inspector.setSize(m.TotalWidth-6, m.RHeight-5)
+		m.review.setSize(m.TotalWidth-6, m.RHeight-5)
+		m.messages.Width, m.messages.Height = m.TotalWidth-6, m.RHeight-5
+		m.refreshMessages()
+	case noticeMsg:
+		m.addNotice(serverNotice(msg))
+		return m, waitForNotice(m.noticeCh)
+	case notificationMsg:
+		return m, m.addNotification(msg)
+	case watchTickMsg:
+		return m.updateWatchTick(msg)
+	case activityTickMsg:
+		return m.updateActivityTick(msg)
+	case lockTickMsg:
+		return m.updateLockTick(msg)
+	case metadataMsg:
+		return m.updateMetadata(msg)
+	case catalogObjectsMsg:
+		return m.updateCatalogObjects(msg)
+	case healthTickMsg:
+		return m.updateHealthTick(msg)
+	case maintenanceDoneMsg:
+		return m.finishMaintenance(msg)
+	case profileDoneMsg:
+		return m.finishProfile(msg)
+	case tea.KeyMsg:
+		m.statusMessage = ""
+		if msg.String() == "ctrl+o" && m.currentPGError() != nil {
+			m.showErrorPanel = !m.showErrorPanel
+			return m, nil
+		}
+	}
+
+	if m.showImport {
+		switch msg.(type) {
+		case tea.KeyMsg, importProgressMsg, importDoneMsg:
+			return m.updateImport(msg)
+		}
+	}
+
+	if msg, ok := msg.(tea.KeyMsg); ok && m.showNotify {
+		return m.updateNotify(msg)
+	}
+	if msg, ok := msg.(tea.KeyMsg); ok && m.showActivity {
+		return m.updateActivity(msg)
+	}
+	if msg, ok := msg.(tea.KeyMsg); ok && m.showLocks {
+		return m.updateLocks(msg)
+	}
+	if msg, ok := msg.(tea.KeyMsg); ok && m.showStatements {
+		return m.updateStatementInsights(msg)
+	}
+	if msg, ok := msg.(tea.KeyMsg); ok && m.showHealth {
+		return m.updateHealth(msg)
+	}
+	if msg, ok := msg.(tea.KeyMsg); ok && m.showFinder {
+		return m.updateFinder(msg)
+	}
+	if msg, ok := msg.(tea.KeyMsg); ok && m.showER {
+		return m.updateERDiagram(msg)
 	}
 
 	if m.showResults && m.focusState == focusResults {
 		switch msg := msg.(type) {
 		case tea.KeyMsg:
+			if m.showMessages {
+				return m.updateMessages(msg)
+			}
+			if m.showProfile {
+				return m.updateProfile(msg)
+			}
+			if m.showChart {
+				return m.updateChart(msg)
+			}
+			if m.showInspector {
+				return m.updateInspector(msg)
+			}
+			if m.showColumnPicker {
+				return m.updateColumnPicker(msg)
+			}
+			if m.showReview {
+				return m.updateReview(msg)
+			}
+			if m.showFKPicker {
+				return m.updateFKPicker(msg)
+			}
+			if m.showExport {
+				return m.updateInsertExport(msg)
+			}
+			if m.edits.editing {
+				return m.updateCellEdit(msg)
+			}
+			if m.browsing {
+				if browsed, cmd, handled := m.updateBrowser(msg); handled {
+					return browsed, cmd
+				}
+			}
+			if m.watch.active {
+				if watched, cmd, handled := m.updateWatch(msg); handled {
+					return watched, cmd
+				}
+			}
 			switch msg.String() {
+			case "left", "h":
+				m.moveColumnCursor(-1)
+			case "right", "l":
+				m.moveColumnCursor(1)
+			case "enter":
+				m.openInspector()
+			case "y":
+				value, _ := m.result.cell(m.resultsTable.Cursor(), m.selectedColumn())
+				m.copyToClipboard(value, "cell")
+			case "Y":
+				m.copyToClipboard(m.result.rowText(m.resultsTable.Cursor()), "row")
+			case "c":
+				m.copyToClipboard(m.result.columnText(m.selectedColumn()), "column")
+			case "F":
+				m.toggleFrozenColumns()
+			case "P":
+				m.pinPrimaryKey()
+			case "v":
+				m.showColumnPicker = true
+				m.pickerCursor = 0
+			case "e":
+				return m, m.beginCellEdit()
+			case "N":
+				m.setCellNull()
+			case "a":
+				m.insertRow()
+			case "x":
+				m.toggleDeleteRow()
+			case "R":
+				m.openReview()
+			case "U":
+				m.discardEdits()
+			case ">":
+				m.followForeignKey()
+			case "<":
+				m.showReferencingRows()
+			case "backspace":
+				m.popCrumb()
+			case "m":
+				m.toggleMarkedRow()
+			case "M":
+				m.marked = nil
+				m.layoutResults()
+			case "I":
+				m.openInsertExport()
+			case "C":
+				m.openChart()
+			case "n":
+				m.openMessages()
 			case "esc":
 				m.showResults = false
+				m.browsing = false
 				m.focusState = focusEditor
 			case "tab":
 				m.focusState = focusEditor
@@ -143,6 +356,8 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "esc":
 			m.queryError = ""
 			m.resultWindow = false
+			m.showErrorPanel = false
+			m.clearErrorMark()
 		case "tab":
 			switch m.focusState {
 			case focusEditor:
@@ -167,31 +382,120 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "backspace":
 			if m.focusState != focusEditor && m.insideColumns {
 				m.currentTable = ""
-				tables, _ := getTables(m.db)
-				var items []list.Item
-				for _, table := range tables {
-					items = append(items, list.Item(table))
+				m.currentSchema = ""
+				m.metadata.pending = nil
+				m.listParents = m.listParents[:max(len(m.listParents)-1, 0)]
+				m.insideColumns = len(m.listParents) > 0
+				key, cached := m.currentListKey()
+				if children, ok := m.metadata.items[key]; ok && cached {
+					m.setListItems(children)
+				} else if n := len(m.listParents); n > 0 {
+					m.setListItems(m.listParents[n-1].child)
+				} else {
+					m.setListItems(nil)
+				}
+				if _, ok := m.metadata.items[key]; cached && !ok {
+					return m, m.loadMetadata(key)
 				}
-				m.dbList.SetItems(items)
-				m.insideColumns = false
 			}
 		case "enter":
 			if m.focusState != focusEditor {
-				selectedItem := m.dbList.SelectedItem().(dbItem)
-				if selectedItem.kind == "tables" {
-					m.currentTable = selectedItem.name
-					columns, _ := getColumns(m.db, selectedItem.name)
-					selectedItem.child = columns
+				selectedItem, ok := m.dbList.SelectedItem().(dbItem)
+				if !ok {
+					break
 				}
-
-				if len(selectedItem.child) > 0 {
-					var items []list.Item
-					for _, child := range selectedItem.child {
-						items = append(items, list.Item(child))
+				if key, ok := metadataKeyFor(selectedItem); ok {
+					if selectedItem.kind == "tables" {
+						m.currentTable = selectedItem.name
+						m.currentSchema = selectedItem.schema
+					}
+					if selectedItem.kind == "db" {
+						if err := m.prepareDatabase(selectedItem.name); err != nil {
+							m.setQueryError(err)
+							break
+						}
 					}
-					m.dbList.SetItems(items)
-					m.insideColumns = true
+					return m, m.enterMetadata(selectedItem, key, "")
 				}
+
+				var err error
+				switch selectedItem.kind {
+				case "settings":
+					selectedItem.child, err = getSettings(m.db)
+				case "roles":
+					selectedItem.child, err = getRoles(m.db)
+				case "setting":
+					m.showSetting(selectedItem.name)
+				case "role":
+					m.showRole(selectedItem.name)
+				}
+				if err != nil {
+					m.setQueryError(err)
+				}
+
+				m.showChildren(selectedItem)
+			}
+		case "o":
+			if m.focusState == focusList && m.dbList.FilterState() != list.Filtering {
+				if m.insideColumns && m.currentTable != "" {
+					m.openTableBrowser(m.currentSchema, m.currentTable)
+				} else if item, ok := m.dbList.SelectedItem().(dbItem); ok && item.kind == "tables" {
+					m.openTableBrowser(item.schema, item.name)
+				}
+				return m, nil
+			}
+		case "i":
+			if m.focusState == focusList && m.dbList.FilterState() != list.Filtering {
+				return m, m.openImport()
+			}
+		case "n":
+			if m.focusState == focusList && m.dbList.FilterState() != list.Filtering {
+				return m, m.openNotifyMonitor()
+			}
+		case "a":
+			if m.focusState == focusList && m.dbList.FilterState() != list.Filtering {
+				return m, m.openActivityMonitor()
+			}
+		case "L":
+			if m.focusState == focusList && m.dbList.FilterState() != list.Filtering {
+				return m, m.openLockViewer()
+			}
+		case "S":
+			if m.focusState == focusList && m.dbList.FilterState() != list.Filtering {
+				m.openStatementInsights()
+				return m, nil
+			}
+		case "M":
+			if m.focusState == focusList && m.dbList.FilterState() != list.Filtering {
+				m.openHealthDashboard()
+				return m, nil
+			}
+		case "D":
+			if m.focusState == focusList && m.dbList.FilterState() != list.Filtering {
+				if m.insideColumns && m.currentTable != "" {
+					m.showCreateStatement(m.currentSchema, m.currentTable)
+				} else if item, ok := m.dbList.SelectedItem().(dbItem); ok && item.kind == "tables" {
+					m.showCreateStatement(item.schema, item.name)
+				}
+				return m, nil
+			}
+		case "P":
+			if m.focusState == focusList && m.dbList.FilterState() != list.Filtering {
+				if m.insideColumns && m.currentTable != "" {
+					m.showTablePrivileges(m.currentSchema, m.currentTable)
+				} else if item, ok := m.dbList.SelectedItem().(dbItem); ok && item.kind == "tables" {
+					m.showTablePrivileges(item.schema, item.name)
+				}
+				return m, nil
+			}
+		case "E":
+			if m.focusState == focusList && m.dbList.FilterState() != list.Filtering {
+				if m.insideColumns && m.currentTable != "" {
+					m.openERDiagram(m.currentSchema, m.currentTable)
+				} else if item, ok := m.dbList.SelectedItem().(dbItem); ok && item.kind == "tables" {
+					m.openERDiagram(item.schema, item.name)
+				}
+				return m, nil
 			}
 		//case "ctrl+v":
 		//	if m.focusedEditor {
@@ -201,6 +505,14 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		//		}
 		//		m.editor.InsertString(text)
 		//	}
+		case "alt+p", "ctrl+p":
+			// Ctrl+p moves up a line in the editor, so there only Alt+p opens the finder.
+			if msg.String() == "ctrl+p" && m.focusState == focusEditor {
+				break
+			}
+			if m.focusState != focusList || m.dbList.FilterState() != list.Filtering {
+				return m, m.openFinder()
+			}
 		case "ctrl+q":
 			return m, tea.Quit
 		case "ctrl+c":
@@ -248,60 +560,61 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					}
 				}
 			}
+		case "ctrl+l":
+			if m.focusState == focusEditor {
+				value := m.editor.Value()
+				start, end := statementBounds(value, cursorOffset(m.editor))
+				if start == end {
+					break
+				}
+				m.editor.SetValue(value[:start] + formatSQL(value[start:end], formatOptionsFromEnv()) + value[end:])
+				moveCursorToOffset(&m.editor, start)
+				return m, nil
+			}
+		case "ctrl+g":
+			if m.focusState == focusEditor && strings.TrimSpace(m.editor.Value()) != "" {
+				m.editor.SetValue(formatStatements(m.editor.Value(), formatOptionsFromEnv()))
+				moveCursorToOffset(&m.editor, 0)
+				return m, nil
+			}
+		case "ctrl+r":
+			if m.focusState == focusEditor {
+				if m.watch.active {
+					m.stopWatch()
+					return m, nil
+				}
+				if query, _ := extractCurrentStatement(m.editor); query != "" {
+					m.clearErrorMark()
+					return m, m.startWatch(query)
+				}
+				return m, nil
+			}
+			if m.focusState == focusList && m.dbList.FilterState() != list.Filtering {
+				m.statusMessage = "Reloading the catalog…"
+				return m, m.refreshMetadata()
+			}
 		case "ctrl+y":
 			if m.focusState == focusEditor {
-				currentQuery := extractCurrentLine(m.editor)
-				currentQuery = strings.TrimSpace(currentQuery)
+				currentQuery, start := extractCurrentStatement(m.editor)
 				if currentQuery != "" {
-					rows, err := m.db.Query(currentQuery)
+					m.clearErrorMark()
+					rs, err := runQuery(m.db, currentQuery)
 					if err != nil {
-						m.queryError = err.Error()
+						m.setQueryError(err)
+						m.markErrorPosition(currentQuery, start)
 						break
 					}
-					defer rows.Close()
-
-					columns, _ := rows.Columns()
-					tableColumns := make([]table.Column, len(columns))
-					for i, col := range columns {
-						tableColumns[i] = table.Column{
-							Title: col,
-							Width: len(col) + 2,
-						}
-					}
-
-					m.resultsTable = table.New(
-						table.WithColumns(tableColumns),
-						table.WithRows([]table.Row{}),
-						table.WithWidth(m.EWidth),
-						table.WithHeight(m.RHeight),
-					)
-
-					var tableRows []table.Row
-					for rows.Next() {
-						values := make([]interface{}, len(columns))
-						valuePtrs := make([]interface{}, len(columns))
-						for i := range values {
-							valuePtrs[i] = &values[i]
-						}
-						if err := rows.Scan(valuePtrs...); err != nil {
-							log.Println("Error scan:", err)
-							break
-						}
-
-						row := make([]string, len(values))
-						for i, val := range values {
-							row[i] = fmt.Sprintf("%v", val)
-						}
-						tableRows = append(tableRows, row)
-					}
+					rs.source = singleTableSource(currentQuery)
+					rs.query = currentQuery
+					m.stopWatch()
+					m.browsing = false
+					m.trail = nil
 
-					m.resultsTable.SetColumns(tableColumns)
-					m.resultsTable.SetRows(tableRows)
-					m.showResults = true
-					m.queryError = ""
-					m.currentPage = 0
-					m.updateResultsTable(tableRows)
+					m.setResults(rs)
 					SaveTableState(m.resultsTable)
+					if isSchemaChange(currentQuery) {
+						return m, m.refreshMetadata()
+					}
 				}
 			}
 		}
@@ -310,6 +623,9 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch m.focusState {
 	case focusEditor:
 		m.editor, cmd = m.editor.Update(msg)
+		if m.errorMark.buffer != "" && m.editor.Value() != m.errorMark.buffer {
+			m.clearErrorMark()
+		}
 	case focusList:
 		m.dbList, cmd = m.dbList.Update(msg)
 	case focusResults:
@@ -355,7 +671,7 @@ func (m model) View() string {
 	mainSection := lipgloss.JoinHorizontal(
 		lipgloss.Top,
 		listStyle.Render(m.dbList.View()),
-		editorStyle.Render(highlightForEditor(m.editor.View())),
+		editorStyle.Render(m.underlineError(highlightForEditor(m.editor.View()))),
 	)
 
 	resultsStyle := lipgloss.NewStyle().
@@ -371,11 +687,49 @@ func (m model) View() string {
 		Height(m.RHeight - 4)
 
 	resultsContent := ""
-	if m.showResults {
-		resultsContent = tableContentStyle.Render(m.resultsTable.View())
+	if m.showImport {
+		resultsContent = tableContentStyle.Render(m.importView())
+	} else if m.showNotify {
+		resultsContent = tableContentStyle.Render(m.notifyView())
+	} else if m.showActivity {
+		resultsContent = tableContentStyle.Render(m.activityView())
+	} else if m.showLocks {
+		resultsContent = tableContentStyle.Render(m.lockView())
+	} else if m.showStatements {
+		resultsContent = tableContentStyle.Render(m.statementInsightsView())
+	} else if m.showHealth {
+		resultsContent = tableContentStyle.Render(m.healthView())
+	} else if m.showFinder {
+		resultsContent = tableContentStyle.Render(m.finderView())
+	} else if m.showER {
+		resultsContent = tableContentStyle.Render(m.erView())
+	} else if m.showErrorPanel && m.currentPGError() != nil {
+		resultsContent = tableContentStyle.Render(m.errorPanelView())
+	} else if m.showInspector {
+		resultsContent = tableContentStyle.Render(m.inspector.View())
+	} else if m.showColumnPicker {
+		resultsContent = tableContentStyle.Render(m.columnPickerView())
+	} else if m.showReview {
+		resultsContent = tableContentStyle.Render(m.review.View())
+	} else if m.showExport {
+		resultsContent = tableContentStyle.Render(m.insertExportView())
+	} else if m.showFKPicker {
+		resultsContent = tableContentStyle.Render(m.fkPickerView())
+	} else if m.showMessages {
+		resultsContent = tableContentStyle.Render(m.messagesView())
+	} else if m.showProfile {
+		resultsContent = tableContentStyle.Render(m.profileView())
+	} else if m.showChart && m.showResults {
+		resultsContent = tableContentStyle.Render(m.chartView())
+	} else if m.showResults {
+		content := m.resultsTable.View()
+		if header := m.resultsHeader(); header != "" {
+			content = header + "\n" + content
+		}
+		resultsContent = tableContentStyle.Render(content)
 	}
 
-	if m.focusState == focusResults {
+	if m.focusState == focusResults || m.showImport || m.showNotify || m.showActivity || m.showLocks || m.showStatements || m.showHealth || m.showFinder || m.showER {
 		resultsStyle = resultsStyle.
 			BorderForeground(lipgloss.Color("5")).
 			Background(lipgloss.Color("235"))
@@ -384,6 +738,10 @@ func (m model) View() string {
 	resultsSection := resultsStyle.Render(resultsContent)
 
 	statusBar := ""
+	errorPanelHint := ""
+	if m.currentPGError() != nil && !m.showErrorPanel {
+		errorPanelHint = "  (ctrl+o: details)"
+	}
 	if m.queryError != "" {
 		statusBar = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("255")).
@@ -391,7 +749,22 @@ func (m model) View() string {
 			Bold(true).
 			Padding(0, 1).
 			Width(totalWidth).
-			Render("Error: " + m.queryError)
+			Render("Error: " + m.queryError + errorPanelHint)
+	} else if m.statusMessage != "" {
+		statusBar = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("10")).
+			Width(totalWidth).
+			Render(m.statusMessage)
+	} else if m.showResults && m.focusState == focusResults {
+		statusBar = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("6")).
+			Width(totalWidth).
+			Render(m.cellPosition())
+	} else if m.notify.unread > 0 {
+		statusBar = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("6")).
+			Width(totalWidth).
+			Render(fmt.Sprintf("%d new notifications (n in the database list)", m.notify.unread))
 	} else if m.currentTable != "" {
 		statusBar = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("6")).
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// metadataKey names one level of the database list: the top (the zero
+// key), the tables of a database or the columns of a table.
+type metadataKey struct {
+	kind   string // "", "tables" or "columns"
+	schema string
+	name   string
+}
+
+// metadataCache holds the catalog levels already loaded for the current
+// connection, so moving around the list doesn't query the database again.
+// Levels load in the background; a load carries the generation it was
+// started for and is dropped when the cache was reset since.
+type metadataCache struct {
+	items         map[metadataKey][]dbItem
+	objects       []catalogObject // Everything the object finder searches, nil until loaded
+	generation    int
+	pending       *dbItem // item entered while its level was loading
+	pendingKey    metadataKey
+	pendingColumn string // column to select once the pending level shows
+}
+
+type metadataMsg struct {
+	generation int
+	key        metadataKey
+	items      []dbItem
+	err        error
+}
+
+func newMetadataCache() metadataCache {
+	return metadataCache{items: map[metadataKey][]dbItem{}}
+}
+
+// reset forgets everything, e.g. after switching databases or running DDL.
+func (c *metadataCache) reset() {
+	c.items = map[metadataKey][]dbItem{}
+	c.objects = nil
+	c.generation++
+	c.pending = nil
+}
+
+// metadataKeyFor returns the key of the level an item opens, for the items
+// whose children are catalog metadata.
+func metadataKeyFor(item dbItem) (metadataKey, bool) {
+	switch item.kind {
+	case "tables":
+		return metadataKey{kind: "columns", schema: item.schema, name: item.name}, true
+	case "db":
+		return metadataKey{kind: "tables", name: item.name}, true
+	}
+	return metadataKey{}, false
+}
+
+func loadMetadata(db *sql.DB, generation int, key metadataKey) tea.Cmd {
+	return func() tea.Msg {
+		var items []dbItem
+		var err error
+		switch key.kind {
+		case "":
+			items, err = getRootItems(db)
+		case "tables":
+			if driver, ok := activeDriver.(databaseDriver); ok {
+				items, err = driver.databaseTables(db, key.name)
+			}
+		case "columns":
+			items, err = activeDriver.columns(db, key.schema, key.name)
+		}
+		return metadataMsg{generation: generation, key: key, items: items, err: err}
+	}
+}
+
+func (m *model) loadMetadata(key metadataKey) tea.Cmd {
+	return loadMetadata(m.db, m.metadata.generation, key)
+}
+
+// currentListKey is the key of the level the list shows.
+func (m model) currentListKey() (metadataKey, bool) {
+	if len(m.listParents) == 0 {
+		return metadataKey{}, true
+	}
+	return metadataKeyFor(m.listParents[len(m.listParents)-1])
+}
+
+func (m *model) setListItems(children []dbItem) {
+	items := make([]list.Item, len(children))
+	for i, child := range children {
+		items[i] = child
+	}
+	m.dbList.SetItems(items)
+}
+
+// showChildren moves the list one level down into item.
+func (m *model) showChildren(item dbItem) {
+	if len(item.child) == 0 {
+		return
+	}
+	m.setListItems(item.child)
+	m.listParents = append(m.listParents, item)
+	m.insideColumns = true
+}
+
+// selectColumn moves the list cursor to the named column.
+func (m *model) selectColumn(column string) {
+	if column == "" {
+		return
+	}
+	for i, listItem := range m.dbList.Items() {
+		if item, ok := listItem.(dbItem); ok && item.column == column {
+			m.dbList.Select(i)
+			return
+		}
+	}
+}
+
+// enterMetadata opens item from the cache, or loads its level and opens it
+// once it arrives. column, if set, is selected in the opened level.
+func (m *model) enterMetadata(item dbItem, key metadataKey, column string) tea.Cmd {
+	if items, ok := m.metadata.items[key]; ok {
+		item.child = items
+		m.showChildren(item)
+		m.selectColumn(column)
+		return nil
+	}
+	m.metadata.pending, m.metadata.pendingKey, m.metadata.pendingColumn = &item, key, column
+	m.statusMessage = "Loading " + item.name + "…"
+	return m.loadMetadata(key)
+}
+
+// cachedColumns returns the columns of a table, querying and caching them
+// when they aren't loaded yet.
+func (m *model) cachedColumns(schema, table string) ([]dbItem, error) {
+	key := metadataKey{kind: "columns", schema: schema, name: table}
+	if items, ok := m.metadata.items[key]; ok {
+		return items, nil
+	}
+	items, err := activeDriver.columns(m.db, schema, table)
+	if err == nil {
+		m.metadata.items[key] = items
+	}
+	return items, err
+}
+
+// refreshMetadata drops the cache and reloads the levels on screen, and the
+// object list when the finder is open.
+func (m *model) refreshMetadata() tea.Cmd {
+	m.metadata.reset()
+	cmds := []tea.Cmd{m.loadMetadata(metadataKey{})}
+	if key, ok := m.currentListKey(); ok && key != (metadataKey{}) {
+		cmds = append(cmds, m.loadMetadata(key))
+	}
+	if m.showFinder {
+		m.filterObjects()
+		cmds = append(cmds, loadCatalogObjects(m.db, m.metadata.generation))
+	}
+	return tea.Batch(cmds...)
+}
+
+func (m model) updateMetadata(msg metadataMsg) (tea.Model, tea.Cmd) {
+	if msg.generation != m.metadata.generation {
+		return m, nil
+	}
+	pending := m.metadata.pending != nil && m.metadata.pendingKey == msg.key
+	if pending {
+		m.statusMessage = ""
+	}
+	if msg.err != nil {
+		if pending {
+			m.metadata.pending = nil
+		}
+		m.setQueryError(msg.err)
+		return m, nil
+	}
+
+	m.metadata.items[msg.key] = msg.items
+	if pending {
+		item := *m.metadata.pending
+		m.metadata.pending = nil
+		item.child = msg.items
+		m.showChildren(item)
+		m.selectColumn(m.metadata.pendingColumn)
+	} else if key, ok := m.currentListKey(); ok && key == msg.key {
+		m.setListItems(msg.items)
+		if n := len(m.listParents); n > 0 {
+			m.listParents[n-1].child = msg.items
+		}
+	}
+	return m, nil
+}
+
+// isSchemaChange reports whether statement can change the catalog.
+func isSchemaChange(statement string) bool {
+	for _, token := range tokenizeSQL(statement) {
+		if token.kind == tokenWord {
+			switch strings.ToUpper(token.text) {
+			case "CREATE", "ALTER", "DROP", "RENAME", "COMMENT", "IMPORT", "ATTACH", "DETACH":
+				return true
+			}
+			return false
+		}
+	}
+	return false
+}
@@ -0,0 +1,20 @@
+package main
+
+import "testing"
+
+func TestMysqlDSN(t *testing.T) {
+	tests := []struct {
+		url, want string
+	}{
+		{"mysql://u:p@localhost/shop", "u:p@tcp(localhost:3306)/shop?clientFoundRows=true&parseTime=true"},
+		{"mysql://u@[::1]/db", "u@tcp([::1]:3306)/db?clientFoundRows=true&parseTime=true"},
+		{"mysql://u:p@h:3307/db?parseTime=false&tls=false", "u:p@tcp(h:3307)/db?clientFoundRows=true&tls=false"},
+		{"mysql://u@h/db?clientFoundRows=false", "u@tcp(h:3306)/db?parseTime=true"},
+	}
+	for _, tt := range tests {
+		got, err := mysqlDSN(tt.url)
+		if err != nil || got != tt.want {
+			t.Errorf("mysqlDSN(%q) = %q, %v, want %q", tt.url, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const maxNotices = 1000
+
+// serverNotice is a NOTICE, WARNING, INFO, ... message the server sent while
+// a statement ran, e.g. from RAISE NOTICE in a DO block.
+type serverNotice struct {
+	at       time.Time
+	severity string
+	message  string
+	detail   string
+	hint     string
+	context  string
+}
+
+type noticeMsg serverNotice
+
+// noticeHandler is called by the driver on the goroutine running the
+// statement; it hands notices to the program without blocking the query.
+// Notices that don't fit in the channel are only counted in dropped.
+func noticeHandler(notices chan<- serverNotice, dropped *atomic.Int64) func(serverNotice) {
+	return func(notice serverNotice) {
+		notice.at = time.Now()
+		select {
+		case notices <- notice:
+		default:
+			dropped.Add(1)
+		}
+	}
+}
+
+func waitForNotice(notices <-chan serverNotice) tea.Cmd {
+	return func() tea.Msg {
+		return noticeMsg(<-notices)
+	}
+}
+
+func (m *model) addNotice(notice serverNotice) {
+	m.droppedNotices += int(m.noticeDrops.Swap(0))
+	m.notices = append(m.notices, notice)
+	if len(m.notices) > maxNotices {
+		m.notices = m.notices[len(m.notices)-maxNotices:]
+	}
+	if m.showMessages {
+		m.refreshMessages()
+	} else {
+		m.unreadNotices++
+	}
+	// Statements like DO blocks have nothing but their notices to show.
+	if !m.showResults || len(m.result.columns) == 0 {
+		m.openMessages()
+	}
+}
+
+func (m *model) openMessages() {
+	m.showResults = true
+	m.showMessages = true
+	m.unreadNotices = 0
+	m.refreshMessages()
+	m.messages.GotoBottom()
+}
+
+func (m *model) refreshMessages() {
+	atBottom := m.messages.AtBottom()
+	m.messages.SetContent(m.noticeLines())
+	if atBottom {
+		m.messages.GotoBottom()
+	}
+}
+
+func severityStyle(severity string) lipgloss.Style {
+	switch severity {
+	case "WARNING":
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Bold(true)
+	case "NOTICE":
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("6")).Bold(true)
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+}
+
+func (m model) noticeLines() string {
+	if len(m.notices) == 0 {
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("245")).Render("No messages from the server")
+	}
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+	width := max(m.messages.Width, 1)
+
+	var lines []string
+	if m.droppedNotices > 0 {
+		lines = append(lines, severityStyle("WARNING").Render(
+			fmt.Sprintf("%d notices dropped: the server sent them faster than they could be shown", m.droppedNotices)))
+	}
+	for _, n := range m.notices {
+		line := dim.Render(n.at.Format("15:04:05.000")) + "  " +
+			severityStyle(n.severity).Render(fmt.Sprintf("%-8s", n.severity)) + " " + n.message
+		lines = append(lines, ansi.Hardwrap(line, width, true))
+		for _, extra := range []struct{ label, value string }{
+			{"DETAIL", n.detail},
+			{"HINT", n.hint},
+			{"CONTEXT", n.context},
+		} {
+			if extra.value != "" {
+				lines = append(lines, ansi.Hardwrap(dim.Render(fmt.Sprintf("%22s ", extra.label))+extra.value, width, true))
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// noticesText is the plain text copied with y.
+func (m model) noticesText() string {
+	var lines []string
+	for _, n := range m.notices {
+		lines = append(lines, fmt.Sprintf("%s %s: %s", n.at.Format("15:04:05.000"), n.severity, n.message))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// resultTabs is the tab line shown above the grid once the server has sent
+// messages.
+func (m model) resultTabs() string {
+	active := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("229")).Background(lipgloss.Color("57")).Padding(0, 1)
+	inactive := lipgloss.NewStyle().Foreground(lipgloss.Color("245")).Padding(0, 1)
+
+	messages := fmt.Sprintf("Messages (%d)", len(m.notices))
+	if m.unreadNotices > 0 {
+		messages = fmt.Sprintf("Messages (%d new)", m.unreadNotices)
+	}
+	results, notices := inactive.Render("Results"), inactive.Render(messages)
+	if m.showMessages {
+		notices = active.Render(messages)
+	} else {
+		results = active.Render("Results")
+	}
+	return results + notices + inactive.Render("n: switch")
+}
+
+func (m model) messagesView() string {
+	return m.resultTabs() + "\n" + m.messages.View()
+}
+
+func (m model) updateMessages(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "n", "esc":
+		m.showMessages = false
+		if len(m.result.columns) == 0 {
+			m.showResults = false
+			m.focusState = focusEditor
+			m.editor.Focus()
+		}
+	case "up", "k":
+		m.messages.LineUp(1)
+	case "down", "j":
+		m.messages.LineDown(1)
+	case "pgup", "b":
+		m.messages.ViewUp()
+	case "pgdown", "f", " ":
+		m.messages.ViewDown()
+	case "home", "g":
+		m.messages.GotoTop()
+	case "end", "G":
+		m.messages.GotoBottom()
+	case "c":
+		m.notices = nil
+		m.droppedNotices = 0
+		m.refreshMessages()
+	case "y":
+		m.copyToClipboard(m.noticesText(), "messages")
+	case "tab":
+		m.focusState = focusEditor
+		m.resultsTable.Blur()
+		m.editor.Focus()
+	}
+	return m, nil
+}
@@ -3,17 +3,38 @@ package main
 import (
 	"database/sql"
 	"fmt"
-	"github.com/joho/godotenv"
-	"log"
+	"net/url"
 	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
 )
 
-func connectToPostgres() (*sql.DB, error) {
-	err := godotenv.Load()
+type postgresDriver struct{}
+
+func (postgresDriver) name() string { return "PostgreSQL" }
+
+// connect opens the connection pool; onNotice receives the NOTICE/WARNING
+// messages the server sends on any of its connections.
+func (postgresDriver) connect(dsn string, onNotice func(serverNotice)) (*sql.DB, error) {
+	connector, err := pq.NewConnector(dsn)
 	if err != nil {
-		log.Println("Failed to load .env file, using environment values")
+		return nil, err
 	}
+	db := sql.OpenDB(pq.ConnectorWithNoticeHandler(connector, func(notice *pq.Error) {
+		onNotice(serverNotice{severity: notice.Severity, message: notice.Message,
+			detail: notice.Detail, hint: notice.Hint, context: notice.Where})
+	}))
 
+	if err = db.Ping(); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+func postgresDSN() string {
 	host := os.Getenv("PG_HOST")
 	user := os.Getenv("PG_USER")
 	password := os.Getenv("PG_PASSWORD")
@@ -21,29 +42,18 @@ func connectToPostgres() (*sql.DB, error) {
 	port := os.Getenv("PG_PORT")
 	sslmode := os.Getenv("PG_SSLMODE")
 
-	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s",
+	return fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s",
 		host, user, password, dbname, port, sslmode)
-
-	db, err := sql.Open("postgres", dsn)
-	if err != nil {
-		return nil, err
-	}
-
-	if err = db.Ping(); err != nil {
-		return nil, err
-	}
-
-	fmt.Println("✅ Successfully connected to PostgreSQL")
-	return db, nil
 }
 
-func getColumns(db *sql.DB, tableName string) ([]dbItem, error) {
+func (postgresDriver) columns(db *sql.DB, schemaName, tableName string) ([]dbItem, error) {
 	query := `
 SELECT column_name, data_type
 FROM information_schema.columns
-WHERE table_name = $1`
+WHERE table_schema = $1 AND table_name = $2
+ORDER BY ordinal_position`
 
-	rows, err := db.Query(query, tableName)
+	rows, err := db.Query(query, schemaName, tableName)
 	if err != nil {
 		return nil, err
 	}
@@ -55,17 +65,14 @@ WHERE table_name = $1`
 		if err := rows.Scan(&colName, &colType); err != nil {
 			return nil, err
 		}
-		columns = append(columns, dbItem{
-			name: colName + "\n" + fmt.Sprintf("(%s)", colType),
-			kind: "column",
-		})
+		columns = append(columns, columnItem(schemaName, tableName, colName, colType))
 	}
 
 	return columns, nil
 }
 
-func getTables(db *sql.DB) ([]dbItem, error) {
-	query := `SELECT tablename
+func (postgresDriver) tables(db *sql.DB) ([]dbItem, error) {
+	query := `SELECT schemaname, tablename
                 FROM pg_tables
               WHERE schemaname NOT IN ('pg_catalog', 'information_schema')`
 	rows, err := db.Query(query)
@@ -76,11 +83,194 @@ func getTables(db *sql.DB) ([]dbItem, error) {
 
 	var tables []dbItem
 	for rows.Next() {
-		var tableName string
-		if err := rows.Scan(&tableName); err != nil {
+		var schemaName, tableName string
+		if err := rows.Scan(&schemaName, &tableName); err != nil {
 			return nil, err
 		}
-		tables = append(tables, dbItem{name: tableName, kind: "tables"})
+		tables = append(tables, dbItem{name: tableName, kind: "tables", schema: schemaName})
 	}
 	return tables, nil
 }
+
+func (postgresDriver) primaryKey(db *sql.DB, tableName string) ([]string, error) {
+	query := `
+SELECT a.attname
+FROM pg_index i
+JOIN pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = ANY(i.indkey)
+WHERE i.indrelid = $1::regclass AND i.indisprimary
+ORDER BY array_position(i.indkey::int2[], a.attnum)`
+
+	rows, err := db.Query(query, tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var columns []string
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return nil, err
+		}
+		columns = append(columns, column)
+	}
+	return columns, rows.Err()
+}
+
+const postgresObjectsQuery = `
+SELECT CASE WHEN c.relkind IN ('v', 'm') THEN 'view' ELSE 'table' END, n.nspname, '', c.relname, '',
+       coalesce(obj_description(c.oid, 'pg_class'), '')
+FROM pg_class c
+JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE c.relkind IN ('r', 'p', 'v', 'm', 'f') AND NOT c.relispartition
+  AND n.nspname NOT IN ('pg_catalog', 'information_schema') AND n.nspname !~ '^pg_toast'
+UNION ALL
+SELECT 'column', n.nspname, c.relname, a.attname, '', coalesce(col_description(c.oid, a.attnum), '')
+FROM pg_attribute a
+JOIN pg_class c ON c.oid = a.attrelid
+JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE a.attnum > 0 AND NOT a.attisdropped
+  AND c.relkind IN ('r', 'p', 'v', 'm', 'f') AND NOT c.relispartition
+  AND n.nspname NOT IN ('pg_catalog', 'information_schema') AND n.nspname !~ '^pg_toast'
+UNION ALL
+SELECT CASE p.prokind WHEN 'p' THEN 'procedure' ELSE 'function' END, n.nspname, '', p.proname,
+       pg_get_function_identity_arguments(p.oid), coalesce(obj_description(p.oid, 'pg_proc'), '')
+FROM pg_proc p
+JOIN pg_namespace n ON n.oid = p.pronamespace
+WHERE p.prokind IN ('f', 'p')
+  AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+  AND NOT EXISTS (SELECT 1 FROM pg_depend d WHERE d.classid = 'pg_proc'::regclass AND d.objid = p.oid AND d.deptype = 'e')`
+
+// objects leaves out partitions, whose parent stands for them, and the
+// functions that belong to extensions.
+func (postgresDriver) objects(db *sql.DB) ([]catalogObject, error) {
+	return queryCatalogObjects(db, postgresObjectsQuery)
+}
+
+func (postgresDriver) routineDefinition(db *sql.DB, routine catalogObject) (string, error) {
+	var definition string
+	err := db.QueryRow(`
+SELECT pg_get_functiondef(p.oid)
+FROM pg_proc p
+JOIN pg_namespace n ON n.oid = p.pronamespace
+WHERE n.nspname = $1 AND p.proname = $2 AND pg_get_function_identity_arguments(p.oid) = $3`,
+		routine.schema, routine.name, routine.args).Scan(&definition)
+	return definition, err
+}
+
+func (postgresDriver) databases(db *sql.DB) ([]dbItem, error) {
+	query := `
+SELECT d.datname, pg_get_userbyid(d.datdba),
+       CASE WHEN has_database_privilege(d.datname, 'CONNECT') THEN pg_size_pretty(pg_database_size(d.datname)) ELSE '?' END,
+       d.datname = current_database()
+FROM pg_database d
+WHERE NOT d.datistemplate AND d.datallowconn
+ORDER BY d.datname`
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var databases []dbItem
+	for rows.Next() {
+		var name, owner, size string
+		var current bool
+		if err := rows.Scan(&name, &owner, &size, &current); err != nil {
+			return nil, err
+		}
+		detail := size + " · owner " + owner
+		if current {
+			detail = "● " + detail
+		}
+		databases = append(databases, dbItem{name: name, kind: "db", detail: detail})
+	}
+	return databases, rows.Err()
+}
+
+// databaseTables lists the tables of the connected database; the model
+// switches pools before asking for another one.
+func (d postgresDriver) databaseTables(db *sql.DB, _ string) ([]dbItem, error) {
+	return d.tables(db)
+}
+
+func (postgresDriver) currentDatabase(db *sql.DB) (string, error) {
+	var name string
+	err := db.QueryRow("SELECT current_database()").Scan(&name)
+	return name, err
+}
+
+var dbnamePattern = regexp.MustCompile(`(^|\s)dbname=\S*`)
+
+// databaseDSN handles both postgres:// URLs and key=value DSNs.
+func (postgresDriver) databaseDSN(dsn, database string) (string, error) {
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		u, err := url.Parse(dsn)
+		if err != nil {
+			return "", err
+		}
+		u.Path = "/" + database
+		return u.String(), nil
+	}
+	value := "dbname='" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(database) + "'"
+	if !dbnamePattern.MatchString(dsn) {
+		return dsn + " " + value, nil
+	}
+	return dbnamePattern.ReplaceAllLiteralString(dsn, " "+value), nil
+}
+
+func (postgresDriver) quoteIdentifier(name string) string {
+	return pq.QuoteIdentifier(name)
+}
+
+func (postgresDriver) quoteLiteral(value string) string {
+	return pq.QuoteLiteral(value)
+}
+
+func (postgresDriver) placeholder(n int) string {
+	return "$" + strconv.Itoa(n)
+}
+
+const foreignKeyQuery = `
+SELECT c.conname,
+       c.conrelid::regclass::text,
+       c.confrelid::regclass::text,
+       array_agg(a.attname ORDER BY k.ord),
+       array_agg(af.attname ORDER BY k.ord)
+FROM pg_constraint c
+CROSS JOIN LATERAL unnest(c.conkey, c.confkey) WITH ORDINALITY AS k(attnum, fattnum, ord)
+JOIN pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = k.attnum
+JOIN pg_attribute af ON af.attrelid = c.confrelid AND af.attnum = k.fattnum
+WHERE c.contype = 'f' AND %s = $1::regclass
+GROUP BY c.conname, c.conrelid, c.confrelid
+ORDER BY 2, 1`
+
+// getForeignKeys returns the foreign keys declared on tableName.
+func getForeignKeys(db *sql.DB, tableName string) ([]foreignKey, error) {
+	return queryForeignKeys(db, fmt.Sprintf(foreignKeyQuery, "c.conrelid"), tableName)
+}
+
+// getReferencingKeys returns the foreign keys of other tables that point at
+// tableName.
+func getReferencingKeys(db *sql.DB, tableName string) ([]foreignKey, error) {
+	return queryForeignKeys(db, fmt.Sprintf(foreignKeyQuery, "c.confrelid"), tableName)
+}
+
+func queryForeignKeys(db *sql.DB, query, tableName string) ([]foreignKey, error) {
+	rows, err := db.Query(query, tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []foreignKey
+	for rows.Next() {
+		var key foreignKey
+		if err := rows.Scan(&key.name, &key.table, &key.refTable,
+			pq.Array(&key.columns), pq.Array(&key.refColumns)); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
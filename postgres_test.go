@@ -0,0 +1,19 @@
+package main
+
+import "testing"
+
+func TestDatabaseDSN(t *testing.T) {
+	tests := []struct {
+		dsn, want string
+	}{
+		{"postgres://u:p@h:5432/old?sslmode=disable", "postgres://u:p@h:5432/it%27s%20new?sslmode=disable"},
+		{"host=h dbname=old user=u", `host=h dbname='it\'s new' user=u`},
+		{"host=h user=u", `host=h user=u dbname='it\'s new'`},
+	}
+	for _, tt := range tests {
+		got, err := postgresDriver{}.databaseDSN(tt.dsn, "it's new")
+		if err != nil || got != tt.want {
+			t.Errorf("databaseDSN(%q) = %q, %v, want %q", tt.dsn, got, err, tt.want)
+		}
+	}
+}
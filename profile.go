@@ -0,0 +1,621 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/lib/pq"
+)
+
+// profileSamples are the TABLESAMPLE SYSTEM percentages the profiler cycles
+// through; "" reads the whole table.
+var profileSamples = []string{"", "10", "1"}
+
+const (
+	profileBuckets   = 12
+	profileTopValues = 10
+)
+
+// profileBar is one bar of the profile: a frequent value or a histogram
+// bucket, with its share of all rows.
+type profileBar struct {
+	label    string
+	fraction float64
+	count    float64
+}
+
+type columnProfile struct {
+	source    string // what the numbers come from, e.g. "full scan" or "pg_stats"
+	estimated bool
+	rows      float64
+	nulls     float64
+	distinct  float64
+	min, max  string
+	avgWidth  int // bytes, pg_stats only
+	top       []profileBar
+	lengths   []profileBar // text columns
+	histogram []profileBar // numeric and date columns
+}
+
+// columnProfiler profiles one column of the browsed table, with the
+// browser's filter applied. Exact profiles run in the background and are
+// cancelled on the server when the view closes or a new one starts; a
+// result whose generation is stale is dropped.
+type columnProfiler struct {
+	schema     string
+	table      string
+	column     string
+	dataType   string
+	category   string // "integer", "numeric", "date", "text" or "other"
+	where      string
+	sample     string
+	profile    *columnProfile
+	running    bool
+	cancel     context.CancelFunc
+	generation int
+}
+
+type profileDoneMsg struct {
+	generation int
+	profile    columnProfile
+	err        error
+}
+
+// columnCategory decides from the driver's type name which statistics make
+// sense for a column.
+func columnCategory(dataType string) string {
+	switch strings.ToUpper(dataType) {
+	case "INT2", "INT4", "INT8":
+		return "integer"
+	case "NUMERIC", "FLOAT4", "FLOAT8":
+		return "numeric"
+	case "DATE", "TIMESTAMP", "TIMESTAMPTZ":
+		return "date"
+	case "TEXT", "VARCHAR", "BPCHAR", "NAME":
+		return "text"
+	}
+	return "other"
+}
+
+// values is the statement producing the column as v, over the sample and
+// the browser's filter.
+func (p columnProfiler) values() string {
+	query := "SELECT " + activeDriver.quoteIdentifier(p.column) + " AS v FROM " + qualifiedName(p.schema, p.table)
+	if p.sample != "" {
+		query += " TABLESAMPLE SYSTEM (" + p.sample + ")"
+	}
+	if strings.TrimSpace(p.where) != "" {
+		query += " WHERE (" + p.where + ")"
+	}
+	return query
+}
+
+// histogramValue maps v to the number the histogram is built on, or "" when
+// the column gets no histogram.
+func (p columnProfiler) histogramValue() string {
+	switch p.category {
+	case "integer", "numeric":
+		return "v::float8"
+	case "date":
+		return "extract(epoch FROM v)::float8"
+	}
+	return ""
+}
+
+func (p columnProfiler) sourceName() string {
+	if p.sample != "" {
+		return p.sample + "% sample"
+	}
+	return "full scan"
+}
+
+func (m *model) openProfile() tea.Cmd {
+	if !m.requirePostgres("Column profiling") {
+		return nil
+	}
+	col := m.selectedColumn()
+	if col < 0 {
+		return nil
+	}
+	m.profiler.stop()
+	m.profiler = columnProfiler{
+		schema:     m.browser.schema,
+		table:      m.browser.table,
+		column:     m.result.columns[col],
+		dataType:   m.result.types[col],
+		category:   columnCategory(m.result.types[col]),
+		where:      m.browser.where,
+		generation: m.profiler.generation + 1,
+	}
+	m.showProfile = true
+	m.resultsTable.Blur()
+	return m.startProfile()
+}
+
+func (m *model) closeProfile() {
+	m.showProfile = false
+	m.profiler.stop()
+	m.profiler.generation++
+	m.resultsTable.Focus()
+}
+
+// stop cancels the running profile's queries.
+func (p *columnProfiler) stop() {
+	if p.cancel != nil {
+		p.cancel()
+		p.cancel = nil
+	}
+	p.running = false
+}
+
+// startProfile shows the planner statistics when they describe what is
+// asked for, i.e. the whole table, and computes the profile otherwise.
+func (m *model) startProfile() tea.Cmd {
+	p := &m.profiler
+	if p.sample == "" && strings.TrimSpace(p.where) == "" {
+		profile, found, err := statsProfile(m.db, *p)
+		if err != nil {
+			m.setQueryError(err)
+		} else if found {
+			p.profile = &profile
+			return nil
+		}
+	}
+	return m.runProfile()
+}
+
+func (m *model) runProfile() tea.Cmd {
+	m.profiler.stop()
+	ctx, cancel := context.WithCancel(context.Background())
+	m.profiler.generation++
+	m.profiler.running = true
+	m.profiler.cancel = cancel
+	db, p := m.db, m.profiler
+	return func() tea.Msg {
+		profile, err := computeProfile(ctx, db, p)
+		return profileDoneMsg{generation: p.generation, profile: profile, err: err}
+	}
+}
+
+func (m model) finishProfile(msg profileDoneMsg) (tea.Model, tea.Cmd) {
+	if msg.generation != m.profiler.generation {
+		return m, nil
+	}
+	m.profiler.stop()
+	if msg.err != nil {
+		m.setQueryError(msg.err)
+		return m, nil
+	}
+	m.profiler.profile = &msg.profile
+	return m, nil
+}
+
+// computeProfile reads the values: the counts and bounds, the most frequent
+// values, and a histogram of the lengths or the values themselves.
+func computeProfile(ctx context.Context, db *sql.DB, p columnProfiler) (columnProfile, error) {
+	profile := columnProfile{source: p.sourceName(), estimated: p.sample != ""}
+
+	bounds := "NULL::text, NULL::text"
+	if p.category != "other" {
+		bounds = "min(v)::text, max(v)::text"
+	}
+	var rows, nulls, distinct int64
+	var minValue, maxValue sql.NullString
+	err := db.QueryRowContext(ctx, fmt.Sprintf(`WITH t AS (%s) SELECT count(*), count(*) - count(v), count(DISTINCT v::text), %s FROM t`,
+		p.values(), bounds)).Scan(&rows, &nulls, &distinct, &minValue, &maxValue)
+	if err != nil {
+		return profile, err
+	}
+	profile.rows, profile.nulls, profile.distinct = float64(rows), float64(nulls), float64(distinct)
+	profile.min, profile.max = minValue.String, maxValue.String
+	if rows == 0 {
+		return profile, nil
+	}
+
+	top, err := db.QueryContext(ctx, fmt.Sprintf(`WITH t AS (%s)
+SELECT v::text, count(*) FROM t WHERE v IS NOT NULL GROUP BY 1 ORDER BY 2 DESC, 1 LIMIT %d`, p.values(), profileTopValues))
+	if err != nil {
+		return profile, err
+	}
+	defer top.Close()
+	for top.Next() {
+		var value string
+		var count int64
+		if err := top.Scan(&value, &count); err != nil {
+			return profile, err
+		}
+		profile.top = append(profile.top, profileBar{label: value, count: float64(count), fraction: float64(count) / float64(rows)})
+	}
+	if err := top.Err(); err != nil {
+		return profile, err
+	}
+
+	if p.category == "text" {
+		lengths, err := queryHistogram(ctx, db, p.values(), "length(v)", true)
+		profile.lengths = lengths.result(float64(rows), formatNumber)
+		return profile, err
+	}
+	if value := p.histogramValue(); value != "" {
+		histogram, err := queryHistogram(ctx, db, p.values(), value, p.category == "integer")
+		profile.histogram = histogram.result(float64(rows), p.formatter(histogram.hi-histogram.lo))
+		return profile, err
+	}
+	return profile, nil
+}
+
+// queryHistogram buckets value over equal-width ranges between its minimum
+// and maximum. Integer values get buckets a whole number wide, like
+// newHistogramLayout.
+func queryHistogram(ctx context.Context, db *sql.DB, values, value string, integer bool) (histogramLayout, error) {
+	bucket := fmt.Sprintf("width_bucket(x.x, b.lo, b.hi, %d)", profileBuckets)
+	if integer {
+		bucket = fmt.Sprintf("floor((x.x - b.lo) / ceil((b.hi - b.lo + 1) / %d))::int + 1", profileBuckets)
+	}
+	rows, err := db.QueryContext(ctx, fmt.Sprintf(`WITH t AS (%s),
+x AS (SELECT (%s)::float8 AS x FROM t WHERE v IS NOT NULL),
+b AS (SELECT min(x) AS lo, max(x) AS hi FROM x)
+SELECT b.lo, b.hi, CASE WHEN b.hi = b.lo THEN 1 ELSE %s END, count(*)
+FROM x, b
+GROUP BY 1, 2, 3
+ORDER BY 3`, values, value, bucket))
+	if err != nil {
+		return histogramLayout{}, err
+	}
+	defer rows.Close()
+
+	var histogram histogramLayout
+	for rows.Next() {
+		var lo, hi float64
+		var bucket int
+		var count int64
+		if err := rows.Scan(&lo, &hi, &bucket, &count); err != nil {
+			return histogramLayout{}, err
+		}
+		if histogram.bars == nil {
+			histogram = newHistogramLayout(lo, hi, integer)
+		}
+		histogram.add(bucket-1, float64(count))
+	}
+	return histogram, rows.Err()
+}
+
+// histogramLayout is the bucket layout shared by computed histograms and
+// the ones rebuilt from pg_stats.
+type histogramLayout struct {
+	lo, hi  float64
+	width   float64
+	integer bool
+	bars    []profileBar
+}
+
+func newHistogramLayout(lo, hi float64, integer bool) histogramLayout {
+	n, width := profileBuckets, (hi-lo)/profileBuckets
+	if integer {
+		width = math.Ceil((hi - lo + 1) / profileBuckets)
+		n = int(math.Ceil((hi - lo + 1) / width))
+	}
+	if hi == lo {
+		n = 1
+	}
+	return histogramLayout{lo: lo, hi: hi, width: width, integer: integer, bars: make([]profileBar, n)}
+}
+
+func (h *histogramLayout) add(bucket int, count float64) {
+	if len(h.bars) > 0 {
+		h.bars[max(min(bucket, len(h.bars)-1), 0)].count += count
+	}
+}
+
+// spread adds count spread evenly over [from, to].
+func (h *histogramLayout) spread(from, to, count float64) {
+	if to <= from || h.width == 0 {
+		h.add(h.bucket(from), count)
+		return
+	}
+	for i := h.bucket(from); i <= h.bucket(to) && i < len(h.bars); i++ {
+		lower, upper := h.lo+float64(i)*h.width, h.lo+float64(i+1)*h.width
+		overlap := math.Min(upper, to) - math.Max(lower, from)
+		if overlap > 0 {
+			h.add(i, count*overlap/(to-from))
+		}
+	}
+}
+
+func (h histogramLayout) bucket(value float64) int {
+	if h.width == 0 {
+		return 0
+	}
+	return int((value - h.lo) / h.width)
+}
+
+// result labels the buckets with their ranges and turns the counts into
+// shares of total rows.
+func (h histogramLayout) result(total float64, format func(float64) string) []profileBar {
+	for i := range h.bars {
+		from, to := h.lo+float64(i)*h.width, h.lo+float64(i+1)*h.width
+		last := math.Min(to-1, h.hi)
+		switch {
+		case h.integer && from == last:
+			h.bars[i].label = format(from)
+		case h.integer:
+			h.bars[i].label = format(from) + " – " + format(last)
+		case h.hi == h.lo:
+			h.bars[i].label = format(h.lo)
+		default:
+			h.bars[i].label = format(from) + " – " + format(to)
+		}
+		if total > 0 {
+			h.bars[i].fraction = h.bars[i].count / total
+		}
+	}
+	return h.bars
+}
+
+const columnStatsQuery = `
+SELECT s.null_frac, s.n_distinct, s.avg_width,
+       coalesce(s.most_common_vals::text, '{}'), coalesce(s.most_common_freqs, '{}'),
+       coalesce(s.histogram_bounds::text, '{}'), greatest(c.reltuples, 0)::float8,
+       greatest(t.last_analyze, t.last_autoanalyze)
+FROM pg_stats s
+JOIN pg_class c ON c.oid = $4::regclass
+LEFT JOIN pg_stat_all_tables t ON t.relid = c.oid
+WHERE s.schemaname = $1 AND s.tablename = $2 AND s.attname = $3
+ORDER BY s.inherited DESC
+LIMIT 1`
+
+// statsProfile builds an estimated profile from what ANALYZE collected:
+// the most common values are point masses and the histogram bounds split
+// the remaining rows into equally filled ranges.
+func statsProfile(db *sql.DB, p columnProfiler) (columnProfile, bool, error) {
+	var nullFrac, nDistinct, reltuples float64
+	var avgWidth int
+	var commonText, boundsText string
+	var freqs []float64
+	var analyzed sql.NullTime
+	err := db.QueryRow(columnStatsQuery, p.schema, p.table, p.column, qualifiedName(p.schema, p.table)).Scan(
+		&nullFrac, &nDistinct, &avgWidth, &commonText, pq.Array(&freqs), &boundsText, &reltuples, &analyzed)
+	if err == sql.ErrNoRows {
+		return columnProfile{}, false, nil
+	}
+	if err != nil {
+		return columnProfile{}, false, err
+	}
+
+	profile := columnProfile{source: "pg_stats", estimated: true, rows: reltuples, nulls: nullFrac * reltuples, avgWidth: avgWidth}
+	if analyzed.Valid {
+		profile.source += ", analyzed " + formatDuration(time.Since(analyzed.Time)) + " ago"
+	}
+	profile.distinct = nDistinct
+	if nDistinct < 0 {
+		profile.distinct = -nDistinct * reltuples
+	}
+
+	// Arrays of arrays don't parse as text arrays; such columns get no values.
+	var common, bounds pq.StringArray
+	if common.Scan([]byte(commonText)) != nil || len(common) != len(freqs) {
+		common, freqs = nil, nil
+	}
+	if bounds.Scan([]byte(boundsText)) != nil {
+		bounds = nil
+	}
+	for i, value := range common {
+		profile.top = append(profile.top, profileBar{label: value, fraction: freqs[i], count: freqs[i] * reltuples})
+	}
+	if len(profile.top) > profileTopValues {
+		profile.top = profile.top[:profileTopValues]
+	}
+	if len(bounds) > 0 {
+		profile.min, profile.max = bounds[0], bounds[len(bounds)-1]
+	}
+
+	if p.histogramValue() == "" {
+		return profile, true, nil
+	}
+	// The common values are left out of the bounds, so they can lie outside.
+	var commonValues, commonCounts, boundValues []float64
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for i, value := range common {
+		number, ok := parseProfileValue(p.category, value)
+		if !ok {
+			continue
+		}
+		commonValues = append(commonValues, number)
+		commonCounts = append(commonCounts, freqs[i]*reltuples)
+		if number < lo {
+			lo, profile.min = number, value
+		}
+		if number > hi {
+			hi, profile.max = number, value
+		}
+	}
+	for i, value := range bounds {
+		number, ok := parseProfileValue(p.category, value)
+		if !ok {
+			return profile, true, nil
+		}
+		boundValues = append(boundValues, number)
+		if number < lo {
+			lo, profile.min = number, bounds[i]
+		}
+		if number > hi {
+			hi, profile.max = number, bounds[i]
+		}
+	}
+	if math.IsInf(lo, 0) {
+		return profile, true, nil
+	}
+
+	histogram := newHistogramLayout(lo, hi, p.category == "integer")
+	for i, number := range commonValues {
+		histogram.add(histogram.bucket(number), commonCounts[i])
+	}
+	if len(boundValues) > 1 {
+		rest := 1 - nullFrac
+		for _, freq := range freqs {
+			rest -= freq
+		}
+		share := math.Max(rest, 0) * reltuples / float64(len(boundValues)-1)
+		for i := 1; i < len(boundValues); i++ {
+			histogram.spread(boundValues[i-1], boundValues[i], share)
+		}
+	}
+	profile.histogram = histogram.result(reltuples, p.formatter(hi-lo))
+	return profile, true, nil
+}
+
+// parseProfileValue reads a value as Postgres prints it into the number
+// the histogram uses: the value itself or, for dates, seconds since 1970.
+func parseProfileValue(category, value string) (float64, bool) {
+	if category != "date" {
+		number, err := strconv.ParseFloat(value, 64)
+		return number, err == nil
+	}
+	t, ok := parseTimeValue(value)
+	return float64(t.Unix()), ok
+}
+
+// formatter prints histogram bounds; timestamps get a time of day when the
+// values span less than a week.
+func (p columnProfiler) formatter(span float64) func(float64) string {
+	if p.category != "date" {
+		return formatNumber
+	}
+	layout := "2006-01-02"
+	if p.dataType != "DATE" && span < 7*24*3600 {
+		layout = "2006-01-02 15:04"
+	}
+	return func(value float64) string {
+		return time.Unix(int64(value), 0).UTC().Format(layout)
+	}
+}
+
+func (m model) updateProfile(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		m.closeProfile()
+	case "r", "ctrl+r":
+		m.profiler.sample = ""
+		return m, m.runProfile()
+	case "t":
+		m.profiler.sample = cycleString(profileSamples, m.profiler.sample, 1)
+		return m, m.runProfile()
+	}
+	return m, nil
+}
+
+// profileBars draws labelled bars scaled to the largest one.
+func profileBars(bars []profileBar, width int, estimated bool) []string {
+	labelWidth := 0
+	largest := 0.0
+	for _, bar := range bars {
+		labelWidth = max(labelWidth, ansi.StringWidth(bar.label))
+		largest = math.Max(largest, bar.fraction)
+	}
+	labelWidth = min(labelWidth, width/3)
+	barWidth := max(width-labelWidth-20, 4)
+	approx := ""
+	if estimated {
+		approx = "~"
+	}
+
+	var lines []string
+	style := lipgloss.NewStyle().Foreground(lipgloss.Color("5"))
+	for _, bar := range bars {
+		label := ansi.Truncate(strings.Join(strings.Fields(bar.label), " "), labelWidth, "…")
+		drawn := drawBar(bar.fraction, largest, barWidth)
+		lines = append(lines, fmt.Sprintf("%s %s%s %5.1f%% %s%.0f",
+			label+strings.Repeat(" ", labelWidth-ansi.StringWidth(label)),
+			style.Render(drawn), strings.Repeat(" ", barWidth-ansi.StringWidth(drawn)),
+			bar.fraction*100, approx, bar.count))
+	}
+	return lines
+}
+
+// drawBar is value as a bar of up to width cells, in eighths of a cell.
+func drawBar(value, largest float64, width int) string {
+	if largest <= 0 {
+		return ""
+	}
+	eighths := int(math.Round(value / largest * float64(width*8)))
+	bar := strings.Repeat("█", eighths/8)
+	if rest := eighths % 8; rest > 0 {
+		bar += string([]rune("▏▎▍▌▋▊▉")[rest-1])
+	}
+	return bar
+}
+
+func (m model) profileView() string {
+	p := m.profiler
+	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("6"))
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+	width := m.TotalWidth - 8
+	height := max(m.RHeight-6, 4)
+
+	heading := fmt.Sprintf("Profile of %s.%s (%s)", qualifiedName(p.schema, p.table),
+		activeDriver.quoteIdentifier(p.column), strings.ToLower(p.dataType))
+	if strings.TrimSpace(p.where) != "" {
+		heading += " WHERE " + p.where
+	}
+	lines := []string{title.Render(ansi.Truncate(heading, width, "…"))}
+
+	sample := "full scan"
+	if p.sample != "" {
+		sample = p.sample + "%"
+	}
+	keys := "r: compute exactly · t: sample (" + sample + ") · esc: close"
+	if p.running {
+		lines = append(lines, dim.Render("Profiling on a "+p.sourceName()+"… · "+keys))
+	} else if p.profile != nil {
+		lines = append(lines, dim.Render(p.profile.source+" · "+keys))
+	} else {
+		lines = append(lines, dim.Render(keys))
+	}
+	if p.profile == nil {
+		return strings.Join(lines, "\n")
+	}
+
+	profile := *p.profile
+	approx := ""
+	if profile.estimated {
+		approx = "~"
+	}
+	nullShare := 0.0
+	if profile.rows > 0 {
+		nullShare = profile.nulls / profile.rows * 100
+	}
+	summary := fmt.Sprintf("rows %s%.0f · nulls %.1f%% (%s%.0f) · distinct %s%.0f",
+		approx, profile.rows, nullShare, approx, profile.nulls, approx, profile.distinct)
+	if profile.min != "" || profile.max != "" {
+		summary += " · min " + profile.min + " · max " + profile.max
+	}
+	if profile.avgWidth > 0 {
+		summary += fmt.Sprintf(" · average width %d bytes", profile.avgWidth)
+	}
+	lines = append(lines, ansi.Truncate(summary, width, "…"), "")
+
+	half := (width - 3) / 2
+	left := append([]string{title.Render("Most frequent values")}, profileBars(profile.top, half, profile.estimated)...)
+	var right []string
+	switch {
+	case len(profile.histogram) > 0:
+		right = append([]string{title.Render("Histogram")}, profileBars(profile.histogram, half, profile.estimated)...)
+	case len(profile.lengths) > 0:
+		right = append([]string{title.Render("Lengths")}, profileBars(profile.lengths, half, profile.estimated)...)
+	}
+	if len(profile.top) == 0 {
+		left = append(left, dim.Render("none"))
+	}
+
+	columnStyle := lipgloss.NewStyle().Width(half).MaxHeight(height - len(lines))
+	body := lipgloss.JoinHorizontal(lipgloss.Top,
+		columnStyle.Render(strings.Join(left, "\n")), "   ", columnStyle.Render(strings.Join(right, "\n")))
+	return strings.Join(lines, "\n") + "\n" + body
+}
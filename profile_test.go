@@ -0,0 +1,46 @@
+package main
+
+import "testing"
+
+func histogramLabels(bars []profileBar) []string {
+	var labels []string
+	for _, bar := range bars {
+		labels = append(labels, bar.label)
+	}
+	return labels
+}
+
+func TestHistogramLayout(t *testing.T) {
+	h := newHistogramLayout(1, 100, true)
+	if len(h.bars) != profileBuckets || h.width != 9 {
+		t.Fatalf("1..100 has %d buckets %v wide, want %d buckets 9 wide", len(h.bars), h.width, profileBuckets)
+	}
+	h.add(0, 3)
+	h.add(99, 1) // past the end: counted in the last bucket
+	h.add(-5, 1) // before the start: counted in the first
+	bars := h.result(10, formatNumber)
+	if bars[0].label != "1 – 9" || bars[0].count != 4 || bars[0].fraction != 0.4 {
+		t.Errorf("first bucket = %+v", bars[0])
+	}
+	if last := bars[len(bars)-1]; last.label != "100" || last.count != 1 {
+		t.Errorf("last bucket = %+v", last)
+	}
+
+	small := newHistogramLayout(1, 5, true)
+	if got := histogramLabels(small.result(1, formatNumber)); len(got) != 5 || got[0] != "1" || got[4] != "5" {
+		t.Errorf("1..5 buckets = %q, want one per value", got)
+	}
+
+	single := newHistogramLayout(5, 5, true)
+	single.add(0, 2)
+	if got := single.result(2, formatNumber); len(got) != 1 || got[0].label != "5" || got[0].fraction != 1 {
+		t.Errorf("a single value = %+v", got)
+	}
+
+	spread := newHistogramLayout(0, 12, false)
+	spread.spread(0, 2, 4)
+	bars = spread.result(4, formatNumber)
+	if bars[0].count != 2 || bars[1].count != 2 || bars[2].count != 0 || bars[0].label != "0 – 1" {
+		t.Errorf("spreading 0..2 = %+v", bars[:3])
+	}
+}
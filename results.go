@@ -0,0 +1,283 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/table"
+)
+
+// resultSet keeps the full, untruncated output of a query so the grid can be
+// re-rendered and cells inspected without going back to the database.
+type resultSet struct {
+	columns []string
+	types   []string // database type names as reported by the driver
+	source  string   // table the rows come from, when the query reads a single one
+	query   string
+	rows    []table.Row
+	nulls   [][]bool
+	// values holds the rows as scanned, for when cells are sent back as
+	// arguments: the text shown in the grid doesn't round-trip for types
+	// like bytea or timestamptz.
+	values [][]interface{}
+}
+
+func scanResultSet(rows *sql.Rows) (resultSet, error) {
+	var rs resultSet
+
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return rs, err
+	}
+	for _, ct := range columnTypes {
+		rs.columns = append(rs.columns, ct.Name())
+		rs.types = append(rs.types, ct.DatabaseTypeName())
+	}
+
+	for rows.Next() {
+		values := make([]interface{}, len(rs.columns))
+		valuePtrs := make([]interface{}, len(rs.columns))
+		for i := range values {
+			valuePtrs[i] = &values[i]
+		}
+		if err := rows.Scan(valuePtrs...); err != nil {
+			return rs, err
+		}
+
+		row := make(table.Row, len(values))
+		nulls := make([]bool, len(values))
+		for i, val := range values {
+			row[i] = formatValue(val, rs.types[i])
+			nulls[i] = val == nil
+		}
+		rs.rows = append(rs.rows, row)
+		rs.nulls = append(rs.nulls, nulls)
+		rs.values = append(rs.values, values)
+	}
+
+	return rs, rows.Err()
+}
+
+// keyValues returns the scanned values of the named columns of row, or false
+// when one of them is missing from the result or NULL.
+func (rs resultSet) keyValues(row int, columns []string) ([]interface{}, bool) {
+	if row >= len(rs.values) {
+		return nil, false
+	}
+	values := make([]interface{}, len(columns))
+	for i, name := range columns {
+		col := indexOfString(rs.columns, name)
+		if col < 0 || rs.values[row][col] == nil {
+			return nil, false
+		}
+		values[i] = rs.values[row][col]
+	}
+	return values, true
+}
+
+func formatValue(val interface{}, dataType string) string {
+	switch v := val.(type) {
+	case nil:
+		return "NULL"
+	case []byte:
+		return string(v)
+	case time.Time:
+		switch dataType {
+		case "DATE":
+			return v.Format("2006-01-02")
+		case "TIME":
+			return v.Format("15:04:05.999999")
+		case "TIMETZ":
+			return v.Format("15:04:05.999999Z07:00")
+		case "TIMESTAMP", "DATETIME":
+			return v.Format("2006-01-02 15:04:05.999999")
+		}
+		return v.Format("2006-01-02 15:04:05.999999Z07:00")
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// timeLayouts covers the times formatValue writes and the ones Postgres
+// and SQLite return as text.
+var timeLayouts = []string{
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999-07:00:00",
+	"2006-01-02 15:04:05.999999999-07",
+	"2006-01-02 15:04:05.999999999",
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02",
+}
+
+func parseTimeValue(value string) (time.Time, bool) {
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// formatNumber prints a number short enough for an axis label.
+func formatNumber(value float64) string {
+	return strconv.FormatFloat(value, 'g', 6, 64)
+}
+
+func (rs resultSet) cell(row, col int) (string, bool) {
+	if row < 0 || row >= len(rs.rows) || col < 0 || col >= len(rs.columns) {
+		return "", false
+	}
+	return rs.rows[row][col], true
+}
+
+func (rs resultSet) rowText(row int) string {
+	if row < 0 || row >= len(rs.rows) {
+		return ""
+	}
+	return strings.Join(rs.rows[row], "\t")
+}
+
+func (rs resultSet) columnText(col int) string {
+	if col < 0 || col >= len(rs.columns) {
+		return ""
+	}
+	values := make([]string, len(rs.rows))
+	for i, row := range rs.rows {
+		values[i] = row[col]
+	}
+	return strings.Join(values, "\n")
+}
+
+func (m *model) setResults(rs resultSet) {
+	// Re-running a query or paging keeps the column layout the user set up.
+	if !sameColumns(m.result.columns, rs.columns) {
+		m.grid = newGridLayout(len(rs.columns))
+		m.colCursor = 0
+	}
+	m.result = rs
+	m.marked = nil
+	m.edits = editSession{}
+	m.showReview = false
+	m.showInspector = false
+	m.showColumnPicker = false
+	m.showMessages = false
+
+	m.resultsTable = table.New(
+		table.WithColumns([]table.Column{}),
+		table.WithRows([]table.Row{}),
+		table.WithWidth(m.EWidth),
+		table.WithHeight(m.RHeight),
+	)
+
+	m.showResults = true
+	m.queryError = ""
+	m.currentPage = 0
+	m.layoutResults()
+}
+
+// overlayOpen reports whether a panel is drawn over the results grid.
+func (m model) overlayOpen() bool {
+	return m.showInspector || m.showColumnPicker || m.showReview || m.showExport || m.showFKPicker ||
+		m.showMessages || m.showProfile || m.showChart
+}
+
+// reloadResults runs the statement behind the grid again.
+func (m *model) reloadResults() {
+	if m.browsing {
+		m.fetchBrowserPage()
+		return
+	}
+	if m.result.query == "" {
+		return
+	}
+	rs, err := runQuery(m.db, m.result.query)
+	if err != nil {
+		m.setQueryError(err)
+		return
+	}
+	rs.query, rs.source = m.result.query, m.result.source
+	m.setResults(rs)
+	m.resultsTable.Focus()
+}
+
+func sameColumns(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *model) moveColumnCursor(delta int) {
+	visible := m.grid.visibleColumns()
+	if len(visible) == 0 {
+		return
+	}
+	m.colCursor = clamp(m.colCursor+delta, 0, len(visible)-1)
+	m.layoutResults()
+}
+
+func (m model) cellPosition() string {
+	col := m.selectedColumn()
+	if col < 0 {
+		return "No columns"
+	}
+	position := fmt.Sprintf("Row %d/%d · Column %d/%d %s (%s)",
+		m.resultsTable.Cursor()+1, len(m.result.rows),
+		m.colCursor+1, len(m.grid.visibleColumns()),
+		m.result.columns[col], strings.ToLower(m.result.types[col]))
+	if hidden := len(m.result.columns) - len(m.grid.visibleColumns()); hidden > 0 {
+		position += fmt.Sprintf(" · %d hidden", hidden)
+	}
+	if m.grid.frozen > 0 {
+		position += fmt.Sprintf(" · %d frozen", m.grid.frozen)
+	}
+	if m.edits.active {
+		position += fmt.Sprintf(" · %d pending changes (R: review)", m.edits.pending())
+	}
+	return position
+}
+
+func (m *model) copyToClipboard(text, what string) {
+	if err := clipboard.WriteAll(text); err != nil {
+		m.queryError = fmt.Sprintf("clipboard: %v", err)
+		return
+	}
+	m.statusMessage = "Copied " + what + " to clipboard"
+}
+
+var (
+	fromPattern     = regexp.MustCompile(`(?is)^\s*select\s.+?\sfrom\s(.+)$`)
+	fromTailPattern = regexp.MustCompile(`(?is)\s(where|group\s+by|having|order\s+by|limit|offset|fetch|for)\s.*$|;.*$`)
+	tableRefPattern = regexp.MustCompile(`(?i)^\s*((?:"[^"]+"|\x60[^\x60]+\x60|[a-z_][\w$]*)(?:\.(?:"[^"]+"|\x60[^\x60]+\x60|[a-z_][\w$]*))?)(?:\s+(?:as\s+)?[a-z_]\w*)?\s*$`)
+	groupingPattern = regexp.MustCompile(`(?i)\b(group\s+by|union|intersect|except|distinct)\b|\(\s*select\b`)
+)
+
+// singleTableSource returns the table a plain "SELECT ... FROM t [WHERE ...]"
+// reads from. Joins, grouping and subqueries yield "", since their rows can't
+// be mapped back to a single table row.
+func singleTableSource(query string) string {
+	if groupingPattern.MatchString(query) {
+		return ""
+	}
+	match := fromPattern.FindStringSubmatch(query)
+	if match == nil {
+		return ""
+	}
+	from := fromTailPattern.ReplaceAllString(" "+match[1], "")
+	ref := tableRefPattern.FindStringSubmatch(from)
+	if ref == nil {
+		return ""
+	}
+	return ref[1]
+}
@@ -0,0 +1,24 @@
+package main
+
+import "testing"
+
+func TestSingleTableSource(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"select * from users", "users"},
+		{"SELECT a, b FROM public.users u WHERE id = 1", "public.users"},
+		{`select * from "My Table" order by 1;`, `"My Table"`},
+		{"select * from t for update", "t"},
+		{"select * from a join b on true", ""},
+		{"select count(*) from t group by x", ""},
+		{"select * from (select 1) s", ""},
+		{"update t set a = 1", ""},
+	}
+	for _, tt := range tests {
+		if got := singleTableSource(tt.query); got != tt.want {
+			t.Errorf("singleTableSource(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
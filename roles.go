@@ -0,0 +1,184 @@
+package main
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+type role struct {
+	name        string
+	superuser   bool
+	login       bool
+	createDB    bool
+	createRole  bool
+	inherit     bool
+	replication bool
+	bypassRLS   bool
+	connLimit   int
+	validUntil  sql.NullTime
+	memberOf    []string
+	members     []string
+}
+
+// attributes lists the role's attributes the way \du does.
+func (r role) attributes() []string {
+	var attributes []string
+	for _, a := range []struct {
+		set  bool
+		name string
+	}{
+		{r.superuser, "superuser"}, {r.login, "login"}, {r.createDB, "create DB"}, {r.createRole, "create role"},
+		{!r.inherit, "no inherit"}, {r.replication, "replication"}, {r.bypassRLS, "bypass RLS"},
+	} {
+		if a.set {
+			attributes = append(attributes, a.name)
+		}
+	}
+	return attributes
+}
+
+const rolesQuery = `
+SELECT r.rolname, r.rolsuper, r.rolcanlogin, r.rolcreatedb, r.rolcreaterole, r.rolinherit,
+       r.rolreplication, r.rolbypassrls, r.rolconnlimit, r.rolvaliduntil,
+       ARRAY(SELECT g.rolname FROM pg_auth_members m JOIN pg_roles g ON g.oid = m.roleid
+             WHERE m.member = r.oid ORDER BY 1),
+       ARRAY(SELECT u.rolname FROM pg_auth_members m JOIN pg_roles u ON u.oid = m.member
+             WHERE m.roleid = r.oid ORDER BY 1)
+FROM pg_roles r`
+
+func queryRoles(db *sql.DB, query string, args ...interface{}) ([]role, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var roles []role
+	for rows.Next() {
+		var r role
+		if err := rows.Scan(&r.name, &r.superuser, &r.login, &r.createDB, &r.createRole, &r.inherit,
+			&r.replication, &r.bypassRLS, &r.connLimit, &r.validUntil,
+			pq.Array(&r.memberOf), pq.Array(&r.members)); err != nil {
+			return nil, err
+		}
+		roles = append(roles, r)
+	}
+	return roles, rows.Err()
+}
+
+// getRoles lists the roles, leaving out the predefined pg_* ones.
+func getRoles(db *sql.DB) ([]dbItem, error) {
+	roles, err := queryRoles(db, rolesQuery+" WHERE r.rolname !~ '^pg_' ORDER BY 1")
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]dbItem, len(roles))
+	for i, r := range roles {
+		detail := strings.Join(r.attributes(), ", ")
+		if len(r.memberOf) > 0 {
+			detail = strings.TrimPrefix(detail+" · member of "+strings.Join(r.memberOf, ", "), " · ")
+		}
+		items[i] = dbItem{name: r.name, kind: "role", detail: detail}
+	}
+	return items, nil
+}
+
+// showRole puts the role's attributes, memberships and direct table grants
+// in the results.
+func (m *model) showRole(name string) {
+	roles, err := queryRoles(m.db, rolesQuery+" WHERE r.rolname = $1", name)
+	if err != nil {
+		m.setQueryError(err)
+		return
+	}
+	if len(roles) == 0 {
+		m.queryError = "role " + name + " not found"
+		return
+	}
+
+	r := roles[0]
+	connLimit := "unlimited"
+	if r.connLimit >= 0 {
+		connLimit = strconv.Itoa(r.connLimit)
+	}
+	validUntil := "forever"
+	if r.validUntil.Valid {
+		validUntil = r.validUntil.Time.Format("2006-01-02 15:04:05")
+	}
+	pairs := [][2]string{
+		{"role", r.name},
+		{"attributes", strings.Join(r.attributes(), ", ")},
+		{"connection limit", connLimit},
+		{"valid until", validUntil},
+		{"member of", strings.Join(r.memberOf, ", ")},
+		{"members", strings.Join(r.members, ", ")},
+	}
+
+	rows, err := m.db.Query(`
+SELECT table_schema || '.' || table_name,
+       string_agg(privilege_type || CASE WHEN is_grantable = 'YES' THEN ' (grantable)' ELSE '' END, ', ' ORDER BY privilege_type)
+FROM information_schema.role_table_grants
+WHERE grantee = $1 AND table_schema NOT IN ('pg_catalog', 'information_schema')
+GROUP BY 1
+ORDER BY 1`, name)
+	if err != nil {
+		m.setQueryError(err)
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var table, privileges string
+		if err := rows.Scan(&table, &privileges); err != nil {
+			m.setQueryError(err)
+			return
+		}
+		pairs = append(pairs, [2]string{"grant on " + table, privileges})
+	}
+	if err := rows.Err(); err != nil {
+		m.setQueryError(err)
+		return
+	}
+
+	m.stopWatch()
+	m.browsing = false
+	m.trail = nil
+	m.setResults(keyValueResult("", pairs))
+}
+
+// showTablePrivileges shows, for every role, the privileges it effectively
+// has on the table (through membership too) and the ones granted to it
+// directly.
+func (m *model) showTablePrivileges(schema, table string) {
+	if !m.requirePostgres("Table privileges") {
+		return
+	}
+	query := `
+SELECT r.rolname AS role,
+       has_table_privilege(r.oid, c.oid, 'SELECT') AS "select",
+       has_table_privilege(r.oid, c.oid, 'INSERT') AS "insert",
+       has_table_privilege(r.oid, c.oid, 'UPDATE') AS "update",
+       has_table_privilege(r.oid, c.oid, 'DELETE') AS "delete",
+       has_table_privilege(r.oid, c.oid, 'TRUNCATE') AS "truncate",
+       has_table_privilege(r.oid, c.oid, 'REFERENCES') AS "references",
+       has_table_privilege(r.oid, c.oid, 'TRIGGER') AS "trigger",
+       coalesce((SELECT string_agg(g.privilege_type, ', ' ORDER BY g.privilege_type)
+                 FROM information_schema.role_table_grants g
+                 WHERE g.grantee = r.rolname AND g.table_schema = $1 AND g.table_name = $2), '') AS granted
+FROM pg_roles r
+CROSS JOIN (SELECT $3::regclass::oid AS oid) c
+WHERE r.rolname !~ '^pg_'
+ORDER BY r.rolname`
+	rs, err := runQuery(m.db, query, schema, table, qualifiedName(schema, table))
+	if err != nil {
+		m.setQueryError(err)
+		return
+	}
+	m.stopWatch()
+	m.browsing = false
+	m.trail = nil
+	m.setResults(rs)
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+
+	"github.com/charmbracelet/bubbles/table"
+)
+
+type serverSetting struct {
+	name           string
+	setting        string
+	unit           string
+	category       string
+	description    string
+	context        string
+	source         string
+	bootValue      string
+	resetValue     string
+	pendingRestart bool
+}
+
+// modified reports whether the value differs from the built-in default.
+func (s serverSetting) modified() bool {
+	return s.source != "default" && s.source != "override"
+}
+
+const settingsQuery = `
+SELECT name, coalesce(setting, ''), coalesce(unit, ''), category, short_desc, context, source,
+       coalesce(boot_val, ''), coalesce(reset_val, ''), pending_restart
+FROM pg_settings`
+
+func scanSettings(rows *sql.Rows) ([]serverSetting, error) {
+	defer rows.Close()
+	var settings []serverSetting
+	for rows.Next() {
+		var s serverSetting
+		if err := rows.Scan(&s.name, &s.setting, &s.unit, &s.category, &s.description, &s.context,
+			&s.source, &s.bootValue, &s.resetValue, &s.pendingRestart); err != nil {
+			return nil, err
+		}
+		settings = append(settings, s)
+	}
+	return settings, rows.Err()
+}
+
+// getSettings lists pg_settings as list items, the value with its unit
+// converted shown under the name.
+func getSettings(db *sql.DB) ([]dbItem, error) {
+	rows, err := db.Query(settingsQuery + " ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	settings, err := scanSettings(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]dbItem, len(settings))
+	for i, s := range settings {
+		detail := formatSetting(s.setting, s.unit)
+		if s.modified() {
+			detail = "● " + detail + " (" + s.source + ")"
+		}
+		items[i] = dbItem{name: s.name, kind: "setting", detail: detail}
+	}
+	return items, nil
+}
+
+type settingUnit struct {
+	name string
+	size int64
+}
+
+// Units from largest to smallest, memory in bytes and time in milliseconds.
+var (
+	memoryUnits = []settingUnit{{"TB", 1 << 40}, {"GB", 1 << 30}, {"MB", 1 << 20}, {"kB", 1 << 10}, {"B", 1}}
+	timeUnits   = []settingUnit{{"d", 86400000}, {"h", 3600000}, {"min", 60000}, {"s", 1000}, {"ms", 1}}
+)
+
+// formatSetting converts a value counted in unit (e.g. 16384 × 8kB) to the
+// largest unit that keeps it whole, the way SHOW prints it: 128MB.
+func formatSetting(setting, unit string) string {
+	value, err := strconv.ParseInt(setting, 10, 64)
+	if err != nil || unit == "" {
+		return setting
+	}
+	if value <= 0 {
+		return setting // 0 and -1 usually mean off or unlimited
+	}
+
+	if unit == "8kB" {
+		value, unit = value*8, "kB"
+	}
+	for _, units := range [][]settingUnit{memoryUnits, timeUnits} {
+		for _, u := range units {
+			if u.name != unit {
+				continue
+			}
+			value *= u.size
+			for _, larger := range units {
+				if value%larger.size == 0 {
+					return strconv.FormatInt(value/larger.size, 10) + larger.name
+				}
+			}
+		}
+	}
+	return setting + " " + unit
+}
+
+// keyValueResult lays out pairs as a two-column result.
+func keyValueResult(source string, pairs [][2]string) resultSet {
+	rs := resultSet{columns: []string{"field", "value"}, types: []string{"TEXT", "TEXT"}, source: source}
+	for _, pair := range pairs {
+		rs.rows = append(rs.rows, table.Row{pair[0], pair[1]})
+		rs.nulls = append(rs.nulls, []bool{false, false})
+	}
+	return rs
+}
+
+func (m *model) showSetting(name string) {
+	rows, err := m.db.Query(settingsQuery+" WHERE name = $1", name)
+	if err != nil {
+		m.setQueryError(err)
+		return
+	}
+	settings, err := scanSettings(rows)
+	if err != nil {
+		m.setQueryError(err)
+		return
+	}
+	if len(settings) == 0 {
+		m.queryError = fmt.Sprintf("setting %s not found", name)
+		return
+	}
+
+	s := settings[0]
+	pairs := [][2]string{
+		{"name", s.name},
+		{"value", formatSetting(s.setting, s.unit)},
+		{"raw value", s.setting},
+		{"unit", s.unit},
+		{"default", formatSetting(s.bootValue, s.unit)},
+		{"reset value", formatSetting(s.resetValue, s.unit)},
+		{"source", s.source},
+		{"non-default", strconv.FormatBool(s.modified())},
+		{"context", s.context},
+		{"pending restart", strconv.FormatBool(s.pendingRestart)},
+		{"category", s.category},
+		{"description", s.description},
+	}
+	m.stopWatch()
+	m.browsing = false
+	m.trail = nil
+	m.setResults(keyValueResult("", pairs))
+}
@@ -0,0 +1,23 @@
+package main
+
+import "testing"
+
+func TestFormatSetting(t *testing.T) {
+	tests := []struct {
+		setting, unit, want string
+	}{
+		{"16384", "8kB", "128MB"},
+		{"131072", "kB", "128MB"},
+		{"60000", "ms", "1min"},
+		{"3600", "s", "1h"},
+		{"1500", "ms", "1500ms"},
+		{"-1", "kB", "-1"},
+		{"on", "", "on"},
+		{"7", "foo", "7 foo"},
+	}
+	for _, tt := range tests {
+		if got := formatSetting(tt.setting, tt.unit); got != tt.want {
+			t.Errorf("formatSetting(%s, %s) = %s, want %s", tt.setting, tt.unit, got, tt.want)
+		}
+	}
+}
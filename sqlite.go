@@ -0,0 +1,184 @@
+package main
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+
+	_ "modernc.org/sqlite"
+)
+
+// sqliteDriver opens SQLite files with the pure-Go modernc.org/sqlite, so
+// the binary needs no C toolchain. Attached databases show up as schemas.
+type sqliteDriver struct{}
+
+func (sqliteDriver) name() string { return "SQLite" }
+
+func (sqliteDriver) connect(dsn string, _ func(serverNotice)) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err = db.Ping(); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// schemas lists main and the attached databases.
+func (sqliteDriver) schemas(db *sql.DB) ([]string, error) {
+	rows, err := db.Query(`SELECT name FROM pragma_database_list WHERE name <> 'temp' ORDER BY seq`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var schemas []string
+	for rows.Next() {
+		var schema string
+		if err := rows.Scan(&schema); err != nil {
+			return nil, err
+		}
+		schemas = append(schemas, schema)
+	}
+	return schemas, rows.Err()
+}
+
+func (d sqliteDriver) tables(db *sql.DB) ([]dbItem, error) {
+	schemas, err := d.schemas(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var tables []dbItem
+	for _, schema := range schemas {
+		rows, err := db.Query(`SELECT name FROM ` + d.quoteIdentifier(schema) + `.sqlite_master
+			WHERE type = 'table' AND name NOT LIKE 'sqlite\_%' ESCAPE '\' ORDER BY name`)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var table string
+			if err := rows.Scan(&table); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			tables = append(tables, dbItem{name: table, kind: "tables", schema: schema})
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return tables, nil
+}
+
+// objects has no functions to offer: SQLite's are registered by the
+// application, not stored in the file.
+func (d sqliteDriver) objects(db *sql.DB) ([]catalogObject, error) {
+	schemas, err := d.schemas(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var objects []catalogObject
+	for _, schema := range schemas {
+		master := d.quoteIdentifier(schema) + `.sqlite_master`
+		found, err := queryCatalogObjects(db, `
+SELECT m.type, $1, '', m.name, '', ''
+FROM `+master+` m
+WHERE m.type IN ('table', 'view') AND m.name NOT LIKE 'sqlite\_%' ESCAPE '\'
+UNION ALL
+SELECT 'column', $1, m.name, c.name, '', ''
+FROM `+master+` m, pragma_table_info(m.name, $1) c
+WHERE m.type IN ('table', 'view') AND m.name NOT LIKE 'sqlite\_%' ESCAPE '\'`, schema)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, found...)
+	}
+	return objects, nil
+}
+
+func (sqliteDriver) columns(db *sql.DB, schemaName, tableName string) ([]dbItem, error) {
+	rows, err := db.Query(`SELECT name, type FROM pragma_table_info($1, $2) ORDER BY cid`, tableName, schemaName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var columns []dbItem
+	for rows.Next() {
+		var colName, colType string
+		if err := rows.Scan(&colName, &colType); err != nil {
+			return nil, err
+		}
+		columns = append(columns, columnItem(schemaName, tableName, colName, colType))
+	}
+	return columns, rows.Err()
+}
+
+// primaryKey reads the pk positions of table_info. A rowid table without a
+// declared primary key has none, like a Postgres table without one.
+func (sqliteDriver) primaryKey(db *sql.DB, tableName string) ([]string, error) {
+	schema, table := splitQualifiedName(tableName)
+	if schema == "" {
+		schema = "main"
+	}
+	rows, err := db.Query(`SELECT name FROM pragma_table_info($1, $2) WHERE pk > 0 ORDER BY pk`, table, schema)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var columns []string
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return nil, err
+		}
+		columns = append(columns, column)
+	}
+	return columns, rows.Err()
+}
+
+func (sqliteDriver) quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func (sqliteDriver) quoteLiteral(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+func (sqliteDriver) placeholder(n int) string {
+	return "$" + strconv.Itoa(n)
+}
+
+func (d sqliteDriver) createStatement(db *sql.DB, schema, table string) (string, error) {
+	var statement string
+	err := db.QueryRow(`SELECT sql FROM `+d.quoteIdentifier(schema)+`.sqlite_master WHERE type = 'table' AND name = $1`,
+		table).Scan(&statement)
+	return statement, err
+}
+
+// splitQualifiedName splits SQL text like "main"."orders", `shop`.`orders`
+// or orders into its unquoted schema and name.
+func splitQualifiedName(name string) (string, string) {
+	var parts []string
+	for _, token := range tokenizeSQL(name) {
+		switch token.kind {
+		case tokenQuotedIdent:
+			quote := token.text[:1]
+			parts = append(parts, strings.ReplaceAll(strings.TrimSuffix(token.text[1:], quote), quote+quote, quote))
+		case tokenWord:
+			parts = append(parts, token.text)
+		}
+	}
+	switch len(parts) {
+	case 0:
+		return "", ""
+	case 1:
+		return "", parts[0]
+	}
+	return parts[len(parts)-2], parts[len(parts)-1]
+}
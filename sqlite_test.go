@@ -0,0 +1,20 @@
+package main
+
+import "testing"
+
+func TestSplitQualifiedName(t *testing.T) {
+	tests := []struct {
+		name, schema, table string
+	}{
+		{`"main"."orders"`, "main", "orders"},
+		{"`shop`.`orders`", "shop", "orders"},
+		{"orders", "", "orders"},
+		{`"a""b"`, "", `a"b`},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		if schema, table := splitQualifiedName(tt.name); schema != tt.schema || table != tt.table {
+			t.Errorf("splitQualifiedName(%q) = %q, %q, want %q, %q", tt.name, schema, table, tt.schema, tt.table)
+		}
+	}
+}
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+var statementSorts = []string{"total", "mean", "calls", "rows", "reads"}
+
+type statementStat struct {
+	query string
+	calls int64
+	total float64 // ms
+	mean  float64 // ms
+	rows  int64
+	reads int64 // shared blocks read
+}
+
+// statementInsights ranks pg_stat_statements entries. Explaining a
+// statement asks for a value for each of its $n placeholders first.
+type statementInsights struct {
+	stats  []statementStat
+	sortBy string
+	cursor int
+	params []string // values entered so far for the statement being explained
+	count  int      // number of placeholders of that statement
+	input  textinput.Model
+}
+
+func newStatementInsights() statementInsights {
+	input := textinput.New()
+	input.CharLimit = 0
+	return statementInsights{sortBy: "total", input: input}
+}
+
+// getStatementStats reads pg_stat_statements; the timing columns were
+// renamed to *_exec_time in version 1.8 (Postgres 13).
+func getStatementStats(db *sql.DB, sortBy string) ([]statementStat, error) {
+	var installed bool
+	if err := db.QueryRow(`SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'pg_stat_statements')`).Scan(&installed); err != nil {
+		return nil, err
+	}
+	if !installed {
+		return nil, fmt.Errorf("pg_stat_statements is not installed (CREATE EXTENSION pg_stat_statements)")
+	}
+
+	var execTime bool
+	if err := db.QueryRow(`SELECT EXISTS (SELECT 1 FROM pg_attribute
+		WHERE attrelid = 'pg_stat_statements'::regclass AND attname = 'total_exec_time')`).Scan(&execTime); err != nil {
+		return nil, err
+	}
+	total, mean := "total_time", "mean_time"
+	if execTime {
+		total, mean = "total_exec_time", "mean_exec_time"
+	}
+
+	order := map[string]string{"total": total, "mean": mean, "calls": "calls", "rows": "rows", "reads": "shared_blks_read"}[sortBy]
+	rows, err := db.Query(fmt.Sprintf(`
+SELECT query, calls, %s, %s, rows, shared_blks_read
+FROM pg_stat_statements
+WHERE dbid = (SELECT oid FROM pg_database WHERE datname = current_database())
+ORDER BY %s DESC
+LIMIT 500`, total, mean, order))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []statementStat
+	for rows.Next() {
+		var s statementStat
+		if err := rows.Scan(&s.query, &s.calls, &s.total, &s.mean, &s.rows, &s.reads); err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
+
+func (m *model) openStatementInsights() {
+	if !m.requirePostgres("Statement insights") {
+		return
+	}
+	stats, err := getStatementStats(m.db, m.statements.sortBy)
+	if err != nil {
+		m.setQueryError(err)
+		return
+	}
+	m.statements.stats = stats
+	m.statements.cursor = 0
+	m.showStatements = true
+	m.editor.Blur()
+}
+
+func (m *model) closeStatementInsights() {
+	m.showStatements = false
+	m.statements.input.Blur()
+	m.focusState = focusEditor
+	m.dbList.SetFilteringEnabled(false)
+	m.editor.Focus()
+}
+
+// placeholderCount returns the highest $n placeholder used in query.
+func placeholderCount(query string) int {
+	count := 0
+	for _, token := range tokenizeSQL(query) {
+		if token.kind == tokenWord && strings.HasPrefix(token.text, "$") {
+			if n, err := strconv.Atoi(token.text[1:]); err == nil {
+				count = max(count, n)
+			}
+		}
+	}
+	return count
+}
+
+// bindPlaceholders replaces $n with the given SQL text. Only placeholder
+// tokens are touched, never text inside strings or comments.
+func bindPlaceholders(query string, values []string) string {
+	var out strings.Builder
+	for _, token := range tokenizeSQL(query) {
+		if token.kind == tokenWord && strings.HasPrefix(token.text, "$") {
+			if n, err := strconv.Atoi(token.text[1:]); err == nil && n >= 1 && n <= len(values) {
+				out.WriteString(values[n-1])
+				continue
+			}
+		}
+		out.WriteString(token.text)
+	}
+	return out.String()
+}
+
+func (s *statementInsights) askParameter() tea.Cmd {
+	s.input.Prompt = fmt.Sprintf("$%d = ", len(s.params)+1)
+	s.input.Placeholder = "SQL literal, e.g. 42 or 'abc'"
+	s.input.SetValue("")
+	return s.input.Focus()
+}
+
+// explainStatement copies EXPLAIN of the selected statement, with its
+// parameters bound, into the editor and runs it.
+func (m *model) explainStatement() {
+	s := &m.statements
+	statement := bindPlaceholders(strings.TrimSpace(s.stats[s.cursor].query), s.params)
+	explain := "EXPLAIN " + strings.TrimSuffix(statement, ";")
+	s.params = nil
+
+	appendStatement(&m.editor, explain+";")
+	m.closeStatementInsights()
+
+	rs, err := runQuery(m.db, explain)
+	if err != nil {
+		m.setQueryError(err)
+		return
+	}
+	rs.query = explain
+	m.stopWatch()
+	m.browsing = false
+	m.trail = nil
+	m.setResults(rs)
+}
+
+func (m model) updateStatementInsights(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	s := &m.statements
+	if s.input.Focused() {
+		switch msg.String() {
+		case "enter":
+			value := strings.TrimSpace(s.input.Value())
+			if value == "" {
+				value = "NULL"
+			}
+			s.params = append(s.params, value)
+			if len(s.params) < s.count {
+				return m, s.askParameter()
+			}
+			s.input.Blur()
+			m.explainStatement()
+		case "esc":
+			s.params = nil
+			s.input.Blur()
+		default:
+			var cmd tea.Cmd
+			s.input, cmd = s.input.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "esc", "q":
+		m.closeStatementInsights()
+	case "up", "k":
+		s.cursor = max(s.cursor-1, 0)
+	case "down", "j":
+		s.cursor = min(s.cursor+1, max(len(s.stats)-1, 0))
+	case "g", "home":
+		s.cursor = 0
+	case "G", "end":
+		s.cursor = max(len(s.stats)-1, 0)
+	case "s":
+		s.sortBy = cycleString(statementSorts, s.sortBy, 1)
+		m.openStatementInsights()
+	case "ctrl+r":
+		cursor := s.cursor
+		m.openStatementInsights()
+		s.cursor = min(cursor, max(len(s.stats)-1, 0))
+	case "o":
+		if s.cursor < len(s.stats) {
+			appendStatement(&m.editor, strings.TrimSuffix(strings.TrimSpace(s.stats[s.cursor].query), ";")+";")
+			m.closeStatementInsights()
+		}
+	case "e", "enter":
+		if s.cursor < len(s.stats) {
+			s.params = nil
+			s.count = placeholderCount(s.stats[s.cursor].query)
+			if s.count > 0 {
+				return m, s.askParameter()
+			}
+			m.explainStatement()
+		}
+	case "y":
+		if s.cursor < len(s.stats) {
+			m.copyToClipboard(s.stats[s.cursor].query, "statement")
+		}
+	}
+	return m, nil
+}
+
+func formatMillis(ms float64) string {
+	switch {
+	case ms >= 3600000:
+		return fmt.Sprintf("%.1fh", ms/3600000)
+	case ms >= 60000:
+		return fmt.Sprintf("%.1fm", ms/60000)
+	case ms >= 1000:
+		return fmt.Sprintf("%.1fs", ms/1000)
+	}
+	return fmt.Sprintf("%.2fms", ms)
+}
+
+func (m model) statementInsightsView() string {
+	s := m.statements
+	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("6"))
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+	selected := lipgloss.NewStyle().Foreground(lipgloss.Color("229")).Background(lipgloss.Color("57"))
+	width := m.TotalWidth - 8
+	height := max(m.RHeight-6, 4)
+
+	lines := []string{title.Render(fmt.Sprintf("pg_stat_statements · %d statements · sorted by %s", len(s.stats), s.sortBy))}
+	if s.input.Focused() {
+		lines = append(lines, s.input.View())
+	} else {
+		lines = append(lines, dim.Render("s: sort · e/enter: EXPLAIN with parameters · o: copy to editor · y: copy · ctrl+r: refresh · esc: close"))
+	}
+	lines = append(lines, dim.Render(fmt.Sprintf("%10s %10s %10s %10s %10s  %s", "TOTAL", "MEAN", "CALLS", "ROWS", "READS", "QUERY")))
+
+	var rows []string
+	for i, stat := range s.stats {
+		line := fmt.Sprintf("%10s %10s %10d %10d %10d  %s", formatMillis(stat.total), formatMillis(stat.mean),
+			stat.calls, stat.rows, stat.reads, strings.Join(strings.Fields(stat.query), " "))
+		line = ansi.Truncate(line, width, "…")
+		if i == s.cursor {
+			line = selected.Render(line)
+		}
+		rows = append(rows, line)
+	}
+
+	var detail []string
+	if s.cursor < len(s.stats) {
+		wrapped := strings.Split(ansi.Hardwrap(strings.Join(strings.Fields(s.stats[s.cursor].query), " "), width, true), "\n")
+		detail = wrapped[:min(len(wrapped), height/3)]
+	}
+	lines = scrollLines(append(lines, rows...), 3, s.cursor, height-len(detail))
+	return strings.Join(append(lines, detail...), "\n")
+}
@@ -10,7 +10,10 @@ const (
 )
 
 func (m *model) calculateColumnWidths(cols []table.Column, rows []table.Row, availableWidth, minColWidth, maxColWidth int) []int {
-	if len(rows) == 0 || len(cols) == 0 {
+	if len(cols) == 0 {
+		return nil
+	}
+	if len(rows) == 0 {
 		colWidth := availableWidth / len(cols)
 		result := make([]int, len(cols))
 		for i := range result {
@@ -122,36 +125,244 @@ func (m *model) prioritizeColumns(cols []table.Column, rows []table.Row) []int {
 	return priorities
 }
 
-func (m model) updateResultsTable(rows []table.Row) {
+// gridLayout decides which result columns are drawn and in what order. The
+// bubbles table has no notion of horizontal scrolling, so it is only ever
+// handed the slice of columns that currently fits.
+type gridLayout struct {
+	order  []int // result column indexes in display order
+	hidden map[int]bool
+	frozen int // leading visible columns pinned while scrolling
+	offset int // first scrollable visible column on screen
+	shown  []int
+}
+
+func newGridLayout(columns int) gridLayout {
+	order := make([]int, columns)
+	for i := range order {
+		order[i] = i
+	}
+	return gridLayout{order: order, hidden: map[int]bool{}}
+}
+
+func (g gridLayout) visibleColumns() []int {
+	var visible []int
+	for _, col := range g.order {
+		if !g.hidden[col] {
+			visible = append(visible, col)
+		}
+	}
+	return visible
+}
+
+func (m *model) selectedColumn() int {
+	visible := m.grid.visibleColumns()
+	if m.colCursor < 0 || m.colCursor >= len(visible) {
+		return -1
+	}
+	return visible[m.colCursor]
+}
+
+func (m *model) layoutResults() {
+	rows := m.watchRows(m.result.rows)
 	if len(rows) > maxRowsToRender {
 		rows = rows[:maxRowsToRender]
 	}
 
-	m.resultsTable.SetRows(rows)
+	visible := m.grid.visibleColumns()
+	m.colCursor = clamp(m.colCursor, 0, max(len(visible)-1, 0))
+	availableWidth := m.TotalWidth - 6
+	if m.showRowMarkers() {
+		availableWidth -= 3
+	}
+	minColWidth := 4
+	maxColWidth := 50
 
-	if len(rows) > 0 {
-		cols := m.resultsTable.Columns()
-		availableWidth := m.TotalWidth - 6
+	natural := make([]int, len(visible))
+	totalWidth := 0
+	for i, col := range visible {
+		width := len(m.result.columns[col]) + 1
+		for _, row := range rows {
+			width = max(width, len(row[col]))
+		}
+		natural[i] = clamp(width, minColWidth, maxColWidth)
+		totalWidth += natural[i] + 2
+	}
 
-		minColWidth := 4
-		maxColWidth := 50
+	var positions, widths []int
+	if totalWidth <= availableWidth || len(rows) == 0 {
+		for i := range visible {
+			positions = append(positions, i)
+		}
+		cols := make([]table.Column, len(visible))
+		for i, col := range visible {
+			cols[i] = table.Column{Title: " " + m.result.columns[col]}
+		}
+		if len(rows) > 0 && len(visible) > 0 {
+			widths = m.calculateColumnWidths(cols, projectRows(rows, visible), availableWidth, minColWidth, maxColWidth)
+		} else {
+			for i := range visible {
+				widths = append(widths, len(cols[i].Title)+1)
+			}
+		}
+		m.grid.offset = 0
+	} else {
+		positions, widths = m.scrollColumns(natural, availableWidth)
+	}
 
-		colWidths := m.calculateColumnWidths(cols, rows, availableWidth, minColWidth, maxColWidth)
+	m.grid.shown = m.grid.shown[:0]
+	tableColumns := make([]table.Column, len(positions))
+	for i, pos := range positions {
+		m.grid.shown = append(m.grid.shown, visible[pos])
+		tableColumns[i] = table.Column{
+			Title: m.columnTitle(pos, visible[pos]),
+			Width: widths[i],
+		}
+	}
 
-		for i := range cols {
-			cols[i].Width = colWidths[i]
+	projected := projectRows(rows, m.grid.shown)
+	if m.showRowMarkers() {
+		tableColumns = append([]table.Column{{Width: 1}}, tableColumns...)
+		for i := range projected {
+			projected[i] = append(table.Row{m.rowMarker(i)}, projected[i]...)
 		}
-		m.resultsTable.SetColumns(cols)
 	}
+
+	m.resultsTable.SetRows(nil)
+	m.resultsTable.SetColumns(tableColumns)
+	m.resultsTable.SetRows(projected)
+}
+
+// scrollColumns keeps the frozen columns on the left and slides the rest so
+// that the column cursor stays on screen.
+func (m *model) scrollColumns(natural []int, availableWidth int) ([]int, []int) {
+	frozen := min(m.grid.frozen, len(natural))
+	frozenWidth := 0
+	for i := 0; i < frozen; i++ {
+		frozenWidth += natural[i] + 2
+	}
+
+	if m.grid.offset < frozen {
+		m.grid.offset = frozen
+	}
+	if m.colCursor >= frozen {
+		if m.colCursor < m.grid.offset {
+			m.grid.offset = m.colCursor
+		}
+		for m.grid.offset < m.colCursor {
+			width := frozenWidth
+			for i := m.grid.offset; i <= m.colCursor; i++ {
+				width += natural[i] + 2
+			}
+			if width <= availableWidth {
+				break
+			}
+			m.grid.offset++
+		}
+	}
+
+	var positions, widths []int
+	used := 0
+	for i := 0; i < frozen; i++ {
+		positions = append(positions, i)
+		widths = append(widths, natural[i])
+		used += natural[i] + 2
+	}
+	for i := m.grid.offset; i < len(natural); i++ {
+		if used+natural[i]+2 > availableWidth && len(positions) > frozen {
+			break
+		}
+		positions = append(positions, i)
+		widths = append(widths, natural[i])
+		used += natural[i] + 2
+	}
+	return positions, widths
+}
+
+// columnTitle prefixes every title with a one-character slot so moving the
+// cursor or freezing columns never changes column widths.
+func (m *model) columnTitle(pos, col int) string {
+	marker := " "
+	switch {
+	case pos == m.colCursor:
+		marker = "▸"
+	case pos < m.grid.frozen:
+		marker = "•"
+	}
+	return marker + m.result.columns[col]
+}
+
+func projectRows(rows []table.Row, columns []int) []table.Row {
+	projected := make([]table.Row, len(rows))
+	for i, row := range rows {
+		cells := make(table.Row, len(columns))
+		for j, col := range columns {
+			cells[j] = row[col]
+		}
+		projected[i] = cells
+	}
+	return projected
 }
 
 func (m *model) reDrawTable() {
 	tableWidth := m.TotalWidth - 4
 	tableHeight := m.RHeight - 2
+	if m.resultsHeader() != "" {
+		tableHeight--
+	}
 	m.resultsTable.SetWidth(tableWidth)
 	m.resultsTable.SetHeight(tableHeight)
 }
 
+func (m *model) showRowMarkers() bool {
+	return m.edits.active || len(m.marked) > 0
+}
+
+// rowMarker flags a row in the narrow column drawn before the first one:
+// pending edits first, then rows marked for export.
+func (m *model) rowMarker(row int) string {
+	if marker := m.edits.rowMarker(row); marker != " " {
+		return marker
+	}
+	if m.marked[row] {
+		return "●"
+	}
+	return " "
+}
+
+func (m *model) toggleMarkedRow() {
+	row := m.resultsTable.Cursor()
+	if row >= len(m.result.rows) {
+		return
+	}
+	if m.marked == nil {
+		m.marked = map[int]bool{}
+	}
+	if m.marked[row] {
+		delete(m.marked, row)
+	} else {
+		m.marked[row] = true
+	}
+	m.layoutResults()
+	m.resultsTable.MoveDown(1)
+}
+
+// resultsHeader is the optional line drawn above the grid.
+func (m model) resultsHeader() string {
+	switch {
+	case m.edits.editing:
+		return m.edits.input.View()
+	case m.browsing:
+		return m.browserHeader()
+	case m.watch.active:
+		return m.watchHeader()
+	case len(m.trail) > 0:
+		return m.trailView()
+	case len(m.notices) > 0:
+		return m.resultTabs()
+	}
+	return ""
+}
+
 func sum(values ...int) int {
 	total := 0
 	for _, v := range values {
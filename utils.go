@@ -19,6 +19,55 @@ func extractCurrentLine(m textarea.Model) string {
 	return strings.TrimSpace(lines[cursorLine])
 }
 
+// cursorOffset converts the editor cursor to a byte offset into its value.
+func cursorOffset(m textarea.Model) int {
+	lines := strings.Split(m.Value(), "\n")
+	offset := 0
+	for i := 0; i < m.Line() && i < len(lines); i++ {
+		offset += len(lines[i]) + 1
+	}
+	if m.Line() < len(lines) {
+		line := []rune(lines[m.Line()])
+		col := min(m.LineInfo().StartColumn+m.LineInfo().ColumnOffset, len(line))
+		offset += len(string(line[:col]))
+	}
+	return offset
+}
+
+// extractCurrentStatement returns the statement under the cursor and its
+// byte offset; statements are separated by semicolons or blank lines, so they
+// can span several lines.
+func extractCurrentStatement(m textarea.Model) (string, int) {
+	value := m.Value()
+	start, end := statementBounds(value, cursorOffset(m))
+	return value[start:end], start
+}
+
+// appendStatement adds statement at the end of the editor and puts the
+// cursor on it, so Ctrl+y runs it.
+func appendStatement(m *textarea.Model, statement string) {
+	content := strings.TrimRight(m.Value(), "\n")
+	if content != "" {
+		content += "\n"
+	}
+	m.SetValue(content + statement)
+	moveCursorToOffset(m, len(content))
+}
+
+// moveCursorToOffset places the editor cursor at a byte offset into its value.
+func moveCursorToOffset(m *textarea.Model, offset int) {
+	before := m.Value()[:min(offset, len(m.Value()))]
+	line := strings.Count(before, "\n")
+	col := len([]rune(before[strings.LastIndex(before, "\n")+1:]))
+	for m.Line() > line {
+		m.CursorUp()
+	}
+	for m.Line() < line {
+		m.CursorDown()
+	}
+	m.SetCursor(col)
+}
+
 func clearScreen() {
 	var cmd *exec.Cmd
 	if runtime.GOOS == "windows" {
@@ -29,3 +78,16 @@ func clearScreen() {
 	cmd.Stdout = os.Stdout
 	cmd.Run()
 }
+
+func containsString(values []string, s string) bool {
+	return indexOfString(values, s) >= 0
+}
+
+func indexOfString(values []string, s string) int {
+	for i, v := range values {
+		if v == s {
+			return i
+		}
+	}
+	return -1
+}
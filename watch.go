@@ -0,0 +1,241 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const watchHistorySize = 20
+
+var watchIntervals = []time.Duration{time.Second, 2 * time.Second, 5 * time.Second, 10 * time.Second, 30 * time.Second, time.Minute}
+
+// watchState re-runs a statement on an interval, like psql's \watch. Ticks
+// carry the generation they were scheduled for, so stopping or restarting
+// simply makes the pending tick stale.
+type watchState struct {
+	active     bool
+	paused     bool
+	query      string
+	interval   time.Duration
+	generation int
+	runs       int
+	lastRun    time.Time
+	changed    map[[2]int]string // row, column → previous value
+	history    []watchRun
+}
+
+type watchRun struct {
+	at      time.Time
+	columns []string
+	rows    []table.Row
+	nulls   [][]bool
+}
+
+type watchTickMsg struct {
+	generation int
+}
+
+func (w watchState) tick() tea.Cmd {
+	generation := w.generation
+	return tea.Tick(w.interval, func(time.Time) tea.Msg {
+		return watchTickMsg{generation: generation}
+	})
+}
+
+func (m *model) startWatch(query string) tea.Cmd {
+	m.watch = watchState{
+		active:     true,
+		query:      query,
+		interval:   2 * time.Second,
+		generation: m.watch.generation + 1,
+	}
+	if !m.runWatch() {
+		m.watch.active = false
+		return nil
+	}
+	return m.watch.tick()
+}
+
+func (m *model) stopWatch() {
+	if !m.watch.active {
+		return
+	}
+	m.watch.active = false
+	m.watch.changed = nil
+	m.watch.generation++
+	m.layoutResults()
+}
+
+// runWatch runs the watched statement and puts the new result in place,
+// remembering which cells differ from the previous run.
+func (m *model) runWatch() bool {
+	rs, err := runQuery(m.db, m.watch.query)
+	if err != nil {
+		m.setQueryError(err)
+		return m.watch.runs > 0
+	}
+	rs.source = singleTableSource(m.watch.query)
+	rs.query = m.watch.query
+
+	changed := map[[2]int]string{}
+	if m.watch.runs > 0 && sameColumns(m.result.columns, rs.columns) {
+		for row := range rs.rows {
+			for col := range rs.columns {
+				if row >= len(m.result.rows) {
+					changed[[2]int{row, col}] = ""
+				} else if rs.rows[row][col] != m.result.rows[row][col] || rs.nulls[row][col] != m.result.nulls[row][col] {
+					changed[[2]int{row, col}] = m.result.rows[row][col]
+				}
+			}
+		}
+	}
+
+	m.watch.runs++
+	m.watch.lastRun = time.Now()
+	m.watch.changed = changed
+	m.watch.history = append(m.watch.history, watchRun{at: m.watch.lastRun, columns: rs.columns, rows: rs.rows, nulls: rs.nulls})
+	if len(m.watch.history) > watchHistorySize {
+		m.watch.history = m.watch.history[1:]
+	}
+
+	cursor := m.resultsTable.Cursor()
+	m.browsing = false
+	m.trail = nil
+	m.setResults(rs)
+	m.resultsTable.SetCursor(min(cursor, max(len(rs.rows)-1, 0)))
+	if m.focusState == focusResults {
+		m.resultsTable.Focus()
+	}
+	return true
+}
+
+func (m model) updateWatchTick(msg watchTickMsg) (tea.Model, tea.Cmd) {
+	if !m.watch.active || msg.generation != m.watch.generation {
+		return m, nil
+	}
+	// Stop once the grid shows something else, e.g. a followed foreign key.
+	if !m.showResults || m.result.query != m.watch.query {
+		m.stopWatch()
+		return m, nil
+	}
+	// Re-running closes the grid's overlays, so wait until they are closed.
+	if !m.watch.paused && !m.edits.active && !m.overlayOpen() {
+		m.runWatch()
+	}
+	return m, m.watch.tick()
+}
+
+// watchCell decorates a changed cell: numbers show how much they moved, other
+// values are flagged with Δ.
+func (w watchState) watchCell(row, col int, value string) string {
+	previous, ok := w.changed[[2]int{row, col}]
+	if !ok {
+		return value
+	}
+	current, err1 := strconv.ParseFloat(value, 64)
+	before, err2 := strconv.ParseFloat(previous, 64)
+	if err1 != nil || err2 != nil {
+		return value + " Δ"
+	}
+	delta := strconv.FormatFloat(current-before, 'f', -1, 64)
+	if current >= before {
+		return value + " ↑" + delta
+	}
+	return value + " ↓" + strings.TrimPrefix(delta, "-")
+}
+
+// watchRows returns the rows to draw, with changed cells decorated.
+func (m model) watchRows(rows []table.Row) []table.Row {
+	if !m.watch.active || len(m.watch.changed) == 0 {
+		return rows
+	}
+	decorated := make([]table.Row, len(rows))
+	for i, row := range rows {
+		decorated[i] = row
+		copied := false
+		for col, value := range row {
+			if _, ok := m.watch.changed[[2]int{i, col}]; !ok {
+				continue
+			}
+			if !copied {
+				decorated[i] = append(table.Row{}, row...)
+				copied = true
+			}
+			decorated[i][col] = m.watch.watchCell(i, col, value)
+		}
+	}
+	return decorated
+}
+
+func (m *model) changeWatchInterval(step int) {
+	for i, interval := range watchIntervals {
+		if interval >= m.watch.interval {
+			m.watch.interval = watchIntervals[clamp(i+step, 0, len(watchIntervals)-1)]
+			return
+		}
+	}
+	m.watch.interval = watchIntervals[len(watchIntervals)-1]
+}
+
+// openWatchHistory lists the values the selected cell had in the last runs.
+func (m *model) openWatchHistory() {
+	row, col := m.resultsTable.Cursor(), m.selectedColumn()
+	if col < 0 {
+		return
+	}
+	name := m.result.columns[col]
+	var lines []string
+	previous := ""
+	for _, run := range m.watch.history {
+		value := "—"
+		// Runs are matched by column name, as columns can come and go.
+		if i := indexOfString(run.columns, name); i >= 0 && row < len(run.rows) {
+			value = run.rows[row][i]
+		}
+		line := run.at.Format("15:04:05") + "  " + value
+		if a, err1 := strconv.ParseFloat(value, 64); err1 == nil {
+			if b, err2 := strconv.ParseFloat(previous, 64); err2 == nil && a != b {
+				line += fmt.Sprintf("  (%+g)", a-b)
+			}
+		}
+		lines = append(lines, line)
+		previous = value
+	}
+	title := fmt.Sprintf("%s · row %d · last %d runs", name, row+1, len(m.watch.history))
+	m.inspector = newCellInspector(title, strings.Join(lines, "\n"), "TEXT", m.TotalWidth-6, m.RHeight-5)
+	m.showInspector = true
+}
+
+func (m model) updateWatch(msg tea.KeyMsg) (model, tea.Cmd, bool) {
+	switch msg.String() {
+	case "+", "=":
+		m.changeWatchInterval(1)
+	case "-":
+		m.changeWatchInterval(-1)
+	case "p":
+		m.watch.paused = !m.watch.paused
+	case "H":
+		m.openWatchHistory()
+	case "W", "esc":
+		m.stopWatch()
+	default:
+		return m, nil, false
+	}
+	return m, nil, true
+}
+
+func (m model) watchHeader() string {
+	state := fmt.Sprintf("⟳ every %s", m.watch.interval)
+	if m.watch.paused {
+		state = "⏸ paused"
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("6")).Render(fmt.Sprintf(
+		"%s · run %d at %s · %d changed  (+/-: interval · p: pause · H: history · W: stop)",
+		state, m.watch.runs, m.watch.lastRun.Format("15:04:05"), len(m.watch.changed)))
+}